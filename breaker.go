@@ -0,0 +1,189 @@
+package recaptchav3
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is the error returned by Response.Verify when a request was not made because the
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("recaptchav3: circuit breaker is open")
+
+// OpenPolicy decides the result of a verification attempted while the circuit breaker is open.
+type OpenPolicy int
+
+const (
+	// FailClosed denies requests while the circuit is open: Response.Verify returns ErrCircuitOpen.
+	FailClosed OpenPolicy = iota
+	// FailOpen allows requests while the circuit is open: Response.Verify returns nil and
+	// Response.Degraded reports true.
+	FailOpen
+	// FailDegraded returns an error while the circuit is open that callers can detect with
+	// IsUnavailable, leaving the decision to the caller.
+	FailDegraded
+)
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed means requests are made normally.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means requests are not made until the cool-down has passed.
+	CircuitOpen
+	// CircuitHalfOpen means a single probe request is allowed to test whether the endpoint has
+	// recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker stops requests to the siteverify endpoint after consecutive failures. A failure is
+// a network error or a 5xx response after any retries. Once the failure threshold is reached the
+// circuit opens for the cool-down period, after which a single probe request is allowed; if it
+// succeeds the circuit closes, otherwise it opens again.
+//
+// A CircuitBreaker is safe for concurrent use and may be shared by several Clients.
+type CircuitBreaker struct {
+	threshold int
+	coolDown  time.Duration
+	policy    OpenPolicy
+	now       func() time.Time
+
+	mu         sync.Mutex
+	state      CircuitState
+	generation uint64
+	failures   int
+	openedAt   time.Time
+	probing    bool
+}
+
+// NewCircuitBreaker returns a CircuitBreaker that opens after failureThreshold consecutive failures
+// and stays open for coolDown. The policy decides the result of verifications made while open.
+func NewCircuitBreaker(failureThreshold int, coolDown time.Duration, policy OpenPolicy) *CircuitBreaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+
+	return &CircuitBreaker{
+		threshold: failureThreshold,
+		coolDown:  coolDown,
+		policy:    policy,
+		now:       time.Now,
+	}
+}
+
+// WithCircuitBreaker sets the circuit breaker used by the Client. By default there is none.
+func WithCircuitBreaker(cb *CircuitBreaker) Option {
+	return func(c *Client) {
+		c.breaker = cb
+	}
+}
+
+// State returns the current state of the circuit.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitOpen && !cb.now().Before(cb.openedAt.Add(cb.coolDown)) {
+		return CircuitHalfOpen
+	}
+
+	return cb.state
+}
+
+// allow reports whether a request may be made, and returns the generation of the circuit to pass
+// to record with the request's outcome.
+func (cb *CircuitBreaker) allow() (uint64, bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitClosed:
+		return cb.generation, true
+	case CircuitOpen:
+		if cb.now().Before(cb.openedAt.Add(cb.coolDown)) {
+			return cb.generation, false
+		}
+
+		cb.setState(CircuitHalfOpen)
+		cb.probing = true
+
+		return cb.generation, true
+	default:
+		if cb.probing {
+			return cb.generation, false
+		}
+
+		cb.probing = true
+
+		return cb.generation, true
+	}
+}
+
+// record records the outcome of a request allowed by allow in generation. Outcomes from an earlier
+// generation are ignored, as they say nothing about the current state: a request started while
+// closed cannot close the circuit after it opened, nor extend the cool-down. Canceled requests say
+// nothing about the endpoint and only release the half-open probe.
+func (cb *CircuitBreaker) record(generation uint64, failed, canceled bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation != cb.generation {
+		return
+	}
+
+	cb.probing = false
+
+	switch {
+	case canceled:
+	case !failed:
+		cb.setState(CircuitClosed)
+		cb.failures = 0
+	case cb.state == CircuitHalfOpen:
+		cb.open()
+	default:
+		cb.failures++
+		if cb.failures >= cb.threshold {
+			cb.open()
+		}
+	}
+}
+
+func (cb *CircuitBreaker) open() {
+	cb.setState(CircuitOpen)
+	cb.openedAt = cb.now()
+	cb.failures = 0
+}
+
+// setState changes the state of the circuit, starting a new generation if it differs.
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	if cb.state != state {
+		cb.state = state
+		cb.generation++
+	}
+}
+
+// openResponse returns the Response for a request rejected by the open circuit.
+func (cb *CircuitBreaker) openResponse() Response {
+	switch cb.policy {
+	case FailOpen:
+		return Response{degraded: true}
+	case FailDegraded:
+		return Response{err: &errUnavailable{err: ErrCircuitOpen}}
+	default:
+		return Response{err: ErrCircuitOpen}
+	}
+}
@@ -0,0 +1,208 @@
+package recaptchav3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func newTestCircuitBreaker(policy OpenPolicy) (*CircuitBreaker, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2020, 1, 24, 14, 47, 44, 0, time.UTC)}
+
+	cb := NewCircuitBreaker(2, time.Minute, policy)
+	cb.now = clock.now
+
+	return cb, clock
+}
+
+// recordRequest records the outcome of a request allowed by cb.
+func recordRequest(cb *CircuitBreaker, failed, canceled bool) {
+	generation, _ := cb.allow()
+	cb.record(generation, failed, canceled)
+}
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	// arrange
+	cb, clock := newTestCircuitBreaker(FailClosed)
+
+	assertState := func(expected CircuitState) {
+		t.Helper()
+		if actual := cb.State(); expected != actual {
+			t.Errorf("State, want: %v got: %v", expected, actual)
+		}
+	}
+
+	// act/assert
+	assertState(CircuitClosed)
+
+	recordRequest(cb, true, false)
+	assertState(CircuitClosed)
+
+	recordRequest(cb, true, false)
+	assertState(CircuitOpen)
+
+	if _, ok := cb.allow(); ok {
+		t.Error("allow while open, want: false got: true")
+	}
+
+	clock.advance(time.Minute)
+	assertState(CircuitHalfOpen)
+
+	probe, ok := cb.allow()
+	if !ok {
+		t.Error("allow probe, want: true got: false")
+	}
+
+	if _, ok := cb.allow(); ok {
+		t.Error("allow second probe, want: false got: true")
+	}
+
+	cb.record(probe, true, false)
+	assertState(CircuitOpen)
+
+	clock.advance(time.Minute)
+	recordRequest(cb, false, false)
+	assertState(CircuitClosed)
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	// arrange
+	cb, _ := newTestCircuitBreaker(FailClosed)
+
+	// act
+	recordRequest(cb, true, false)
+	recordRequest(cb, false, false)
+	recordRequest(cb, true, false)
+
+	// assert
+	if actual := cb.State(); actual != CircuitClosed {
+		t.Errorf("want: %v got: %v", CircuitClosed, actual)
+	}
+}
+
+func TestCircuitBreaker_CanceledReleasesProbe(t *testing.T) {
+	// arrange
+	cb, clock := newTestCircuitBreaker(FailClosed)
+	recordRequest(cb, true, false)
+	recordRequest(cb, true, false)
+	clock.advance(time.Minute)
+
+	// act
+	recordRequest(cb, false, true)
+
+	// assert
+	if actual := cb.State(); actual != CircuitHalfOpen {
+		t.Errorf("State, want: %v got: %v", CircuitHalfOpen, actual)
+	}
+
+	if _, ok := cb.allow(); !ok {
+		t.Error("allow probe, want: true got: false")
+	}
+}
+
+func TestCircuitBreaker_LateOutcomesIgnored(t *testing.T) {
+	// arrange
+	cb, clock := newTestCircuitBreaker(FailClosed)
+
+	lateSuccess, _ := cb.allow()
+	lateFailure, _ := cb.allow()
+
+	recordRequest(cb, true, false)
+	recordRequest(cb, true, false)
+	openedAt := cb.openedAt
+
+	// act
+	clock.advance(time.Second)
+	cb.record(lateSuccess, false, false)
+	cb.record(lateFailure, true, false)
+
+	clock.advance(time.Minute)
+	probe, _ := cb.allow()
+	cb.record(lateSuccess, false, true)
+
+	// assert
+	if cb.openedAt != openedAt {
+		t.Errorf("openedAt, want: %v got: %v", openedAt, cb.openedAt)
+	}
+
+	if actual := cb.State(); actual != CircuitHalfOpen {
+		t.Errorf("State, want: %v got: %v", CircuitHalfOpen, actual)
+	}
+
+	if _, ok := cb.allow(); ok {
+		t.Error("allow second probe, want: false got: true")
+	}
+
+	cb.record(probe, false, false)
+	if actual := cb.State(); actual != CircuitClosed {
+		t.Errorf("State after probe, want: %v got: %v", CircuitClosed, actual)
+	}
+}
+
+func TestClient_SiteVerify_CircuitBreakerPolicies(t *testing.T) {
+	cases := []struct {
+		testName string
+
+		policy      OpenPolicy
+		expectedErr error
+		degraded    bool
+		unavailable bool
+	}{
+		{testName: "FailClosed", policy: FailClosed, expectedErr: ErrCircuitOpen},
+		{testName: "FailOpen", policy: FailOpen, degraded: true},
+		{testName: "FailDegraded", policy: FailDegraded, expectedErr: ErrCircuitOpen, unavailable: true},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// arrange
+			var count int32
+
+			ts := newFlakyServer(10, http.StatusServiceUnavailable, `{"success":true}`, &count)
+			defer ts.Close()
+
+			cb, _ := newTestCircuitBreaker(tc.policy)
+			client := NewClient(WithURL(ts.URL), WithCircuitBreaker(cb))
+
+			client.SiteVerify(context.Background(), "abc", "")
+			client.SiteVerify(context.Background(), "abc", "")
+
+			// act
+			response := client.SiteVerify(context.Background(), "abc", "")
+			err := response.Verify(defaultAction, defaultMinScore, nil)
+
+			// assert
+			if count := atomic.LoadInt32(&count); count != 2 {
+				t.Errorf("requests, want: 2 got: %d", count)
+			}
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("err, want: '%v' got: '%v'", tc.expectedErr, err)
+			}
+
+			if tc.degraded != response.Degraded() {
+				t.Errorf("Degraded, want: %v got: %v", tc.degraded, response.Degraded())
+			}
+
+			if tc.unavailable != IsUnavailable(err) {
+				t.Errorf("IsUnavailable, want: %v got: %v", tc.unavailable, IsUnavailable(err))
+			}
+		})
+	}
+}
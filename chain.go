@@ -0,0 +1,103 @@
+package recaptchav3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrUnknownProvider is returned by Response.Verify for a response from Chain.SiteVerify when the
+// chain has no Client for the requested provider.
+var ErrUnknownProvider = errors.New("recaptchav3: unknown provider")
+
+// Chain verifies tokens with Clients for different providers, in order of preference. When a
+// provider is unavailable the response is marked to fall back to the next provider, so the user
+// can be shown that provider's CAPTCHA instead. Create one with NewChain.
+//
+// Responses are checked with Response.Verify or Policy.Evaluate as for a single Client. A response
+// that should fall back fails with a *FallbackError, for which Policy.Evaluate returns
+// OutcomeChallenge, and Response.Provider records the provider that produced the response.
+type Chain struct {
+	clients []*Client
+}
+
+// NewChain returns a Chain trying clients in order. Each Client should use a different Provider,
+// set with WithProvider. NewChain panics if no clients are given.
+func NewChain(clients ...*Client) *Chain {
+	if len(clients) == 0 {
+		panic("recaptchav3: NewChain requires a client")
+	}
+
+	return &Chain{clients: append([]*Client{}, clients...)}
+}
+
+// Providers returns the names of the chain's providers in order.
+func (ch *Chain) Providers() []string {
+	names := make([]string, len(ch.clients))
+	for i, c := range ch.clients {
+		names[i] = c.provider.Name()
+	}
+
+	return names
+}
+
+// SiteVerify verifies captchaResponse, a token issued by the named provider, with the chain's
+// Client for that provider. An empty provider selects the first Client.
+//
+// If the provider is unavailable, as reported by IsUnavailable, or its circuit breaker is open, and
+// the chain has a later provider, the response fails with a *FallbackError naming it. This
+// includes a breaker with the FailOpen policy, as the chain has an alternative to allowing the
+// request unverified.
+func (ch *Chain) SiteVerify(ctx context.Context, provider, captchaResponse, remoteIP string) Response {
+	i := ch.index(provider)
+	if i < 0 {
+		return Response{Provider: provider, err: ErrUnknownProvider}
+	}
+
+	c := ch.clients[i]
+	response := c.SiteVerify(ctx, captchaResponse, remoteIP)
+
+	if i == len(ch.clients)-1 || !unavailable(response) {
+		return response
+	}
+
+	err := response.err
+	if response.degraded {
+		err = ErrCircuitOpen
+	}
+
+	return Response{
+		Provider: response.Provider,
+		err:      &FallbackError{Provider: response.Provider, Next: ch.clients[i+1].provider.Name(), Err: err},
+	}
+}
+
+// SiteVerifyRequest is like SiteVerify for a token received in r, as with Client.SiteVerifyRequest.
+func (ch *Chain) SiteVerifyRequest(r *http.Request, provider, captchaResponse string) Response {
+	i := ch.index(provider)
+	if i < 0 {
+		return Response{Provider: provider, err: ErrUnknownProvider}
+	}
+
+	return ch.SiteVerify(r.Context(), provider, captchaResponse, RemoteIP(r, ch.clients[i].trustedProxies))
+}
+
+// index returns the index of the Client for the named provider, or -1.
+func (ch *Chain) index(provider string) int {
+	if provider == "" {
+		return 0
+	}
+
+	for i, c := range ch.clients {
+		if c.provider.Name() == provider {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unavailable reports whether the response was not obtained because the endpoint was unavailable.
+func unavailable(r Response) bool {
+	return r.degraded || IsUnavailable(r.err) || errors.Is(r.err, ErrCircuitOpen)
+}
@@ -0,0 +1,209 @@
+package recaptchav3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestChain(primary, secondary *httptest.Server, opts ...Option) *Chain {
+	return NewChain(
+		NewClient(append([]Option{WithURL(primary.URL)}, opts...)...),
+		NewClient(WithURL(secondary.URL), WithProvider(TurnstileProvider{})),
+	)
+}
+
+func newUnavailableServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+}
+
+func TestChain_SiteVerify_Primary(t *testing.T) {
+	// arrange
+	primary := newScoreServer()
+	defer primary.Close()
+
+	secondary := newScoreServer()
+	defer secondary.Close()
+
+	ch := newTestChain(primary, secondary)
+
+	// act
+	response := ch.SiteVerify(context.Background(), "", "0.9", "")
+
+	// assert
+	if err := response.Verify("login", 0.5, nil); err != nil {
+		t.Errorf("want: <nil> got: '%v'", err)
+	}
+
+	if response.Provider != "google" {
+		t.Errorf("Provider, want: 'google' got: '%v'", response.Provider)
+	}
+}
+
+func TestChain_SiteVerify_Fallback(t *testing.T) {
+	// arrange
+	primary := newUnavailableServer()
+	defer primary.Close()
+
+	secondary := newScoreServer()
+	defer secondary.Close()
+
+	ch := newTestChain(primary, secondary)
+
+	// act
+	err := ch.SiteVerify(context.Background(), "google", "0.9", "").Verify("login", 0.5, nil)
+
+	// assert
+	if !errors.Is(err, ErrFallback) {
+		t.Fatalf("want: '%v' got: '%v'", ErrFallback, err)
+	}
+
+	var target *FallbackError
+	if !errors.As(err, &target) || target.Provider != "google" || target.Next != "turnstile" {
+		t.Errorf("want: google -> turnstile got: '%v'", err)
+	}
+
+	var status *HTTPStatusError
+	if !errors.As(err, &status) || !IsUnavailable(err) {
+		t.Errorf("want: unavailable %T got: '%v'", status, err)
+	}
+}
+
+func TestChain_SiteVerify_Secondary(t *testing.T) {
+	// arrange
+	primary := newUnavailableServer()
+	defer primary.Close()
+
+	secondary := newScoreServer()
+	defer secondary.Close()
+
+	ch := newTestChain(primary, secondary)
+
+	// act
+	response := ch.SiteVerify(context.Background(), "turnstile", "0.9", "")
+
+	// assert
+	if err := response.Verify("login", 0.5, nil); err != nil {
+		t.Errorf("want: <nil> got: '%v'", err)
+	}
+
+	if response.Provider != "turnstile" {
+		t.Errorf("Provider, want: 'turnstile' got: '%v'", response.Provider)
+	}
+}
+
+func TestChain_SiteVerify_LastProviderUnavailable(t *testing.T) {
+	// arrange
+	primary := newScoreServer()
+	defer primary.Close()
+
+	secondary := newUnavailableServer()
+	defer secondary.Close()
+
+	ch := newTestChain(primary, secondary)
+
+	// act
+	err := ch.SiteVerify(context.Background(), "turnstile", "0.9", "").Verify("login", 0.5, nil)
+
+	// assert
+	if !IsUnavailable(err) || errors.Is(err, ErrFallback) {
+		t.Errorf("want: unavailable without fallback got: '%v'", err)
+	}
+}
+
+func TestChain_SiteVerify_CircuitOpen(t *testing.T) {
+	cases := []struct {
+		testName string
+
+		policy OpenPolicy
+	}{
+		{testName: "FailClosed", policy: FailClosed},
+		{testName: "FailOpen", policy: FailOpen},
+		{testName: "FailDegraded", policy: FailDegraded},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// arrange
+			primary := newUnavailableServer()
+			defer primary.Close()
+
+			secondary := newScoreServer()
+			defer secondary.Close()
+
+			ch := newTestChain(primary, secondary, WithCircuitBreaker(NewCircuitBreaker(1, time.Minute, tc.policy)))
+			ch.SiteVerify(context.Background(), "", "0.9", "")
+
+			// act
+			err := ch.SiteVerify(context.Background(), "", "0.9", "").Verify("login", 0.5, nil)
+
+			// assert
+			if !errors.Is(err, ErrFallback) || !errors.Is(err, ErrCircuitOpen) {
+				t.Errorf("want: '%v' '%v' got: '%v'", ErrFallback, ErrCircuitOpen, err)
+			}
+		})
+	}
+}
+
+func TestChain_SiteVerify_UnknownProvider(t *testing.T) {
+	// arrange
+	ts := newScoreServer()
+	defer ts.Close()
+
+	ch := newTestChain(ts, ts)
+
+	// act
+	err := ch.SiteVerify(context.Background(), "hcaptcha", "0.9", "").Verify("login", 0.5, nil)
+
+	// assert
+	if !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("want: '%v' got: '%v'", ErrUnknownProvider, err)
+	}
+}
+
+func TestChain_Providers(t *testing.T) {
+	// arrange
+	ts := newScoreServer()
+	defer ts.Close()
+
+	ch := newTestChain(ts, ts)
+
+	// act
+	providers := ch.Providers()
+
+	// assert
+	if expected := []string{"google", "turnstile"}; !reflect.DeepEqual(expected, providers) {
+		t.Errorf("want: %v got: %v", expected, providers)
+	}
+}
+
+func TestPolicy_Evaluate_Fallback(t *testing.T) {
+	// arrange
+	primary := newUnavailableServer()
+	defer primary.Close()
+
+	secondary := newScoreServer()
+	defer secondary.Close()
+
+	ch := newTestChain(primary, secondary)
+	policy := &Policy{Actions: map[string]Rule{"login": {MinScore: 0.5}}}
+
+	// act
+	d := policy.Evaluate("login", ch.SiteVerify(context.Background(), "", "0.9", ""))
+
+	// assert
+	if !d.Challenged() || !errors.Is(d.Reason, ErrFallback) {
+		t.Errorf("want: %v '%v' got: %v '%v'", OutcomeChallenge, ErrFallback, d.Outcome, d.Reason)
+	}
+
+	if d.Provider != "google" {
+		t.Errorf("Provider, want: 'google' got: '%v'", d.Provider)
+	}
+}
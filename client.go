@@ -0,0 +1,235 @@
+package recaptchav3
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Client makes requests to the reCAPTCHA siteverify endpoint. Create one with NewClient; the zero
+// value is not usable. A Client is safe for concurrent use by multiple goroutines.
+type Client struct {
+	httpClient *http.Client
+	url        string
+	provider   Provider
+
+	endpointFailover bool
+	endpoints        []string
+	// preferred is the index in endpoints of the last endpoint that was reached.
+	preferred int32
+
+	secret          SecretProvider
+	v2Secret        SecretProvider
+	userAgent       string
+	maxResponseSize int64
+	maxTokenLength  int
+
+	retry   RetryPolicy
+	breaker *CircuitBreaker
+
+	trustedProxies []*net.IPNet
+
+	sites   map[string]Site
+	siteIDs []string
+
+	tokens   TokenStore
+	tokenTTL time.Duration
+	flights  *flightGroup
+	limiter  *rateLimiter
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used to make requests. Use it to set timeouts, transports or
+// proxies. The default is http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithURL sets the siteverify endpoint URL. The default is the URL of the Provider,
+// https://www.google.com/recaptcha/api/siteverify unless WithProvider is used.
+func WithURL(url string) Option {
+	return func(c *Client) {
+		c.url = url
+	}
+}
+
+// WithRecaptchaNet sets the siteverify endpoint URL to
+// https://www.recaptcha.net/recaptcha/api/siteverify, for networks where www.google.com is blocked.
+func WithRecaptchaNet() Option {
+	return func(c *Client) {
+		c.url = recaptchaNetVerifyURL
+	}
+}
+
+// WithEndpointFailover fails over between the www.google.com and www.recaptcha.net siteverify
+// endpoints when the endpoint cannot be connected to. The other endpoint is then used until it
+// cannot be connected to either. Requests that fail after connecting are not sent to the other
+// endpoint, as the token may already have been used. It has no effect for other endpoint URLs.
+func WithEndpointFailover() Option {
+	return func(c *Client) {
+		c.endpointFailover = true
+	}
+}
+
+// WithProvider sets the CAPTCHA provider whose siteverify endpoint is used. The secret keys must be
+// those issued by the provider. The default is GoogleProvider.
+func WithProvider(p Provider) Option {
+	return func(c *Client) {
+		c.provider = p
+	}
+}
+
+// WithSecretKey sets the secret key sent with each request.
+func WithSecretKey(secretKey string) Option {
+	return WithSecretProvider(StaticSecret(secretKey))
+}
+
+// WithSecretProvider sets the provider of the secret key sent with each request, for keys that are
+// loaded from the environment or a file, or are being rotated. See RotatingSecret.
+func WithSecretProvider(p SecretProvider) Option {
+	return func(c *Client) {
+		c.secret = p
+	}
+}
+
+// WithV2SecretKey sets the secret key of the reCAPTCHA v2 site used by SiteVerifyV2. v2 checkbox
+// and invisible sites have their own keys, separate from the v3 key set by WithSecretKey.
+func WithV2SecretKey(secretKey string) Option {
+	return WithV2SecretProvider(StaticSecret(secretKey))
+}
+
+// WithV2SecretProvider is like WithSecretProvider for the key set by WithV2SecretKey.
+func WithV2SecretProvider(p SecretProvider) Option {
+	return func(c *Client) {
+		c.v2Secret = p
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with each request. If empty, the default Go HTTP
+// client User-Agent is used.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithMaxResponseSize sets the maximum size in bytes of a response body. Larger successful responses
+// fail with a *ResponseTooLargeError; only the start of larger error responses is read. The default
+// is DefaultMaxResponseSize.
+func WithMaxResponseSize(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseSize = n
+	}
+}
+
+// WithTrustedProxies sets the proxies trusted to report the client IP address in forwarding
+// headers. See RemoteIP and Client.SiteVerifyRequest. Use ParseTrustedProxies to build the list.
+func WithTrustedProxies(trustedProxies []*net.IPNet) Option {
+	return func(c *Client) {
+		c.trustedProxies = trustedProxies
+	}
+}
+
+// NewClient returns a new Client configured with opts.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		httpClient:      http.DefaultClient,
+		provider:        GoogleProvider{},
+		secret:          StaticSecret(""),
+		v2Secret:        StaticSecret(""),
+		maxResponseSize: DefaultMaxResponseSize,
+		maxTokenLength:  DefaultMaxTokenLength,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+
+	if c.secret == nil {
+		c.secret = StaticSecret("")
+	}
+
+	if c.v2Secret == nil {
+		c.v2Secret = StaticSecret("")
+	}
+
+	if c.maxResponseSize <= 0 {
+		c.maxResponseSize = DefaultMaxResponseSize
+	}
+
+	if c.maxTokenLength <= 0 {
+		c.maxTokenLength = DefaultMaxTokenLength
+	}
+
+	if c.provider == nil {
+		c.provider = GoogleProvider{}
+	}
+
+	if c.url == "" {
+		c.url = c.provider.URL()
+	}
+
+	c.endpoints = []string{c.url}
+
+	if c.endpointFailover {
+		switch c.url {
+		case siteVerifyURL:
+			c.endpoints = append(c.endpoints, recaptchaNetVerifyURL)
+		case recaptchaNetVerifyURL:
+			c.endpoints = append(c.endpoints, siteVerifyURL)
+		}
+	}
+
+	return c
+}
+
+// String returns a description of the Client that does not include its secret keys, so logging a
+// Client cannot leak them.
+func (c *Client) String() string {
+	return fmt.Sprintf("recaptchav3.Client{provider: %s, url: %s}", c.provider.Name(), c.url)
+}
+
+// GoString is like String for the %#v verb.
+func (c *Client) GoString() string {
+	return c.String()
+}
+
+// SiteVerify makes a request to the siteverify endpoint using the Client's secret key and returns
+// the response. Use Response.Verify to verify the response.
+//
+// The remoteIP parameter is optional and may be left blank. See RemoteIP for obtaining it from an
+// HTTP request.
+//
+// Tokens that are empty, longer than the limit set by WithMaxTokenLength or contain characters that
+// never appear in tokens are rejected with a *TokenError without making a request.
+func (c *Client) SiteVerify(ctx context.Context, captchaResponse, remoteIP string) Response {
+	return c.siteVerify(ctx, c.secret, captchaResponse, remoteIP)
+}
+
+// SiteVerifyRequest is like SiteVerify for a token received in r. It uses the request context and
+// fills the remote IP with RemoteIP using the proxies set by WithTrustedProxies.
+func (c *Client) SiteVerifyRequest(r *http.Request, captchaResponse string) Response {
+	return c.SiteVerify(r.Context(), captchaResponse, RemoteIP(r, c.trustedProxies))
+}
+
+// SiteVerifyV2 is like SiteVerify for a reCAPTCHA v2 checkbox or invisible token, using the secret
+// key set by WithV2SecretKey. The request shares the Client's HTTP client, retries, circuit breaker
+// and token store. Use Response.VerifyV2 to verify the response.
+func (c *Client) SiteVerifyV2(ctx context.Context, captchaResponse, remoteIP string) Response {
+	return c.siteVerify(ctx, c.v2Secret, captchaResponse, remoteIP)
+}
+
+// SiteVerifyV2Request is like SiteVerifyV2 for a token received in r, as with SiteVerifyRequest.
+func (c *Client) SiteVerifyV2Request(r *http.Request, captchaResponse string) Response {
+	return c.SiteVerifyV2(r.Context(), captchaResponse, RemoteIP(r, c.trustedProxies))
+}
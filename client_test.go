@@ -0,0 +1,234 @@
+package recaptchav3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewClient_Defaults(t *testing.T) {
+	// act
+	c := NewClient()
+
+	// assert
+	if c.httpClient != http.DefaultClient {
+		t.Errorf("httpClient, want: http.DefaultClient got: %v", c.httpClient)
+	}
+
+	if c.url != siteVerifyURL {
+		t.Errorf("url, want: '%v' got: '%v'", siteVerifyURL, c.url)
+	}
+
+	if c.secret != StaticSecret("") {
+		t.Errorf("secret, want: '' got: '%v'", c.secret)
+	}
+}
+
+func TestNewClient_NilHTTPClient(t *testing.T) {
+	// act
+	c := NewClient(WithHTTPClient(nil))
+
+	// assert
+	if c.httpClient != http.DefaultClient {
+		t.Errorf("httpClient, want: http.DefaultClient got: %v", c.httpClient)
+	}
+}
+
+func TestClient_SiteVerify_UserAgent(t *testing.T) {
+	// arrange
+	const expected = "recaptchav3-test/1.0"
+
+	userAgent := make(chan string, 1)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent <- r.UserAgent()
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL), WithUserAgent(expected))
+
+	// act
+	c.SiteVerify(context.Background(), "abc", "")
+
+	// assert
+	if actual := <-userAgent; expected != actual {
+		t.Errorf("want: '%v' got: '%v'", expected, actual)
+	}
+}
+
+func TestClient_SiteVerify_HTTPClientTimeout(t *testing.T) {
+	// arrange
+	ts := newTestServer(time.Now().UTC(), slowHTTPHandler)
+	defer ts.Close()
+
+	c := NewClient(
+		WithURL(ts.URL),
+		WithSecretKey("abc"),
+		WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}),
+	)
+
+	// act
+	response := c.SiteVerify(context.Background(), "def", "")
+
+	// assert
+	if response.err == nil {
+		t.Error("want: timeout error got: <nil>")
+	}
+}
+
+func TestClient_SiteVerifyV2_SecretKey(t *testing.T) {
+	// arrange
+	secrets := make(chan string, 2)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secrets <- r.PostFormValue("secret")
+		w.Write([]byte(`{"success":true,"hostname":"example.com"}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL), WithSecretKey("v3-secret"), WithV2SecretKey("v2-secret"))
+
+	// act
+	v3 := c.SiteVerify(context.Background(), "abc", "")
+	v2 := c.SiteVerifyV2(context.Background(), "def", "")
+
+	// assert
+	if actual := <-secrets; actual != "v3-secret" {
+		t.Errorf("v3 secret, want: 'v3-secret' got: '%v'", actual)
+	}
+
+	if actual := <-secrets; actual != "v2-secret" {
+		t.Errorf("v2 secret, want: 'v2-secret' got: '%v'", actual)
+	}
+
+	if err := v2.VerifyV2([]string{"example.com"}); err != nil {
+		t.Errorf("VerifyV2, want: <nil> got: '%v'", err)
+	}
+
+	if err := v3.Verify(defaultAction, defaultMinScore, nil); !errors.Is(err, ErrActionMismatch) {
+		t.Errorf("Verify, want: '%v' got: '%v'", ErrActionMismatch, err)
+	}
+}
+
+func TestNewClient_Endpoints(t *testing.T) {
+	cases := []struct {
+		testName string
+
+		opts     []Option
+		expected []string
+	}{
+		{testName: "Default", expected: []string{siteVerifyURL}},
+		{testName: "RecaptchaNet", opts: []Option{WithRecaptchaNet()}, expected: []string{recaptchaNetVerifyURL}},
+		{testName: "Failover", opts: []Option{WithEndpointFailover()}, expected: []string{siteVerifyURL, recaptchaNetVerifyURL}},
+		{
+			testName: "RecaptchaNetFailover",
+			opts:     []Option{WithRecaptchaNet(), WithEndpointFailover()},
+			expected: []string{recaptchaNetVerifyURL, siteVerifyURL},
+		},
+		{
+			testName: "OtherProviderFailover",
+			opts:     []Option{WithProvider(TurnstileProvider{}), WithEndpointFailover()},
+			expected: []string{turnstileVerifyURL},
+		},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			client := NewClient(tc.opts...)
+
+			// assert
+			if !reflect.DeepEqual(tc.expected, client.endpoints) {
+				t.Errorf("want: %v got: %v", tc.expected, client.endpoints)
+			}
+		})
+	}
+}
+
+func TestClient_SiteVerify_EndpointFailover(t *testing.T) {
+	// arrange
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	var requests int32
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"success":true,"action":"homepage","score":0.9}`))
+	}))
+	defer up.Close()
+
+	c := NewClient()
+	c.endpoints = []string{down.URL, up.URL}
+
+	for i := 0; i < 2; i++ {
+		// act
+		response := c.SiteVerify(context.Background(), "abc", "")
+
+		// assert
+		if err := response.Verify(defaultAction, defaultMinScore, nil); err != nil {
+			t.Errorf("want: <nil> got: '%v'", err)
+		}
+
+		if response.Endpoint != up.URL {
+			t.Errorf("Endpoint, want: '%v' got: '%v'", up.URL, response.Endpoint)
+		}
+	}
+
+	if c.preferred != 1 || requests != 2 {
+		t.Errorf("preferred, requests, want: 1, 2 got: %d, %d", c.preferred, requests)
+	}
+}
+
+func TestClient_SiteVerify_NoEndpointFailoverAfterConnecting(t *testing.T) {
+	// arrange
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	other := newScoreServer()
+	defer other.Close()
+
+	c := NewClient()
+	c.endpoints = []string{ts.URL, other.URL}
+
+	// act
+	response := c.SiteVerify(context.Background(), "0.9", "")
+
+	// assert
+	if !IsUnavailable(response.err) {
+		t.Errorf("want: unavailable got: '%v'", response.err)
+	}
+
+	if response.Endpoint != ts.URL || requests != 1 {
+		t.Errorf("Endpoint, requests, want: '%v', 1 got: '%v', %d", ts.URL, response.Endpoint, requests)
+	}
+}
+
+func TestClient_String(t *testing.T) {
+	// arrange
+	c := NewClient(WithSecretKey("v3-secret"), WithV2SecretKey("v2-secret"))
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		// act
+		s := fmt.Sprintf(format, c)
+
+		// assert
+		if strings.Contains(s, "secret") {
+			t.Errorf("%s, want: no secret got: '%s'", format, s)
+		}
+	}
+}
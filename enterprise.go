@@ -0,0 +1,298 @@
+package recaptchav3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const enterpriseBaseURL = "https://recaptchaenterprise.googleapis.com"
+
+// EnterpriseClient makes requests to the reCAPTCHA Enterprise REST API using API key
+// authentication. Create one with NewEnterpriseClient. An EnterpriseClient is safe for concurrent
+// use by multiple goroutines.
+//
+// The API key is sent in the X-Goog-Api-Key header rather than the URL so it does not appear in
+// errors, and is redacted from response bodies included in errors.
+type EnterpriseClient struct {
+	httpClient *http.Client
+	baseURL    string
+	projectID  string
+	apiKey     string
+	userAgent  string
+
+	maxResponseSize int64
+}
+
+// EnterpriseOption configures an EnterpriseClient.
+type EnterpriseOption func(*EnterpriseClient)
+
+// WithEnterpriseHTTPClient sets the HTTP client used to make requests. The default is
+// http.DefaultClient.
+func WithEnterpriseHTTPClient(httpClient *http.Client) EnterpriseOption {
+	return func(c *EnterpriseClient) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithEnterpriseBaseURL sets the API base URL, for example to use a local fake. The default is
+// https://recaptchaenterprise.googleapis.com.
+func WithEnterpriseBaseURL(baseURL string) EnterpriseOption {
+	return func(c *EnterpriseClient) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithEnterpriseUserAgent sets the User-Agent header sent with each request.
+func WithEnterpriseUserAgent(userAgent string) EnterpriseOption {
+	return func(c *EnterpriseClient) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithEnterpriseMaxResponseSize sets the maximum size in bytes of a response body, as
+// WithMaxResponseSize does for Client. The default is DefaultMaxResponseSize.
+func WithEnterpriseMaxResponseSize(n int64) EnterpriseOption {
+	return func(c *EnterpriseClient) {
+		c.maxResponseSize = n
+	}
+}
+
+// NewEnterpriseClient returns a new EnterpriseClient for the Google Cloud project projectID
+// authenticating with apiKey.
+func NewEnterpriseClient(projectID, apiKey string, opts ...EnterpriseOption) *EnterpriseClient {
+	c := &EnterpriseClient{
+		httpClient: http.DefaultClient,
+		baseURL:    enterpriseBaseURL,
+		projectID:  projectID,
+		apiKey:     apiKey,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+
+	if c.maxResponseSize <= 0 {
+		c.maxResponseSize = DefaultMaxResponseSize
+	}
+
+	return c
+}
+
+// String returns a description of the EnterpriseClient that does not include its API key.
+func (c *EnterpriseClient) String() string {
+	return fmt.Sprintf("recaptchav3.EnterpriseClient{project: %s}", c.projectID)
+}
+
+// GoString is like String for the %#v verb.
+func (c *EnterpriseClient) GoString() string {
+	return c.String()
+}
+
+// Event is the event assessed by CreateAssessment.
+type Event struct {
+	Token          string `json:"token"`
+	SiteKey        string `json:"siteKey"`
+	ExpectedAction string `json:"expectedAction,omitempty"`
+	UserIPAddress  string `json:"userIpAddress,omitempty"`
+	UserAgent      string `json:"userAgent,omitempty"`
+}
+
+// Assessment is the result of CreateAssessment.
+type Assessment struct {
+	// Name is the assessment resource name, "projects/{project}/assessments/{assessment}".
+	Name            string          `json:"name"`
+	Event           Event           `json:"event"`
+	RiskAnalysis    RiskAnalysis    `json:"riskAnalysis"`
+	TokenProperties TokenProperties `json:"tokenProperties"`
+}
+
+// RiskAnalysis is the risk analysis of an Assessment.
+type RiskAnalysis struct {
+	// Score for this request (0.0 - 1.0).
+	Score float64 `json:"score"`
+	// Reasons contributing to the score, such as "AUTOMATION" or "UNEXPECTED_ENVIRONMENT".
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// TokenProperties are the properties of the token in an Assessment.
+type TokenProperties struct {
+	Valid bool `json:"valid"`
+	// InvalidReason is set if the token is not valid, such as "MALFORMED", "EXPIRED" or "DUPE".
+	InvalidReason      string    `json:"invalidReason,omitempty"`
+	Hostname           string    `json:"hostname,omitempty"`
+	AndroidPackageName string    `json:"androidPackageName,omitempty"`
+	Action             string    `json:"action,omitempty"`
+	CreateTime         time.Time `json:"createTime"`
+}
+
+// invalidReasonCodes maps token invalid reasons to the equivalent siteverify error codes.
+var invalidReasonCodes = map[string]ErrorCode{
+	"MALFORMED":     ErrorCodeInvalidInputResponse,
+	"EXPIRED":       ErrorCodeTimeoutOrDuplicate,
+	"DUPE":          ErrorCodeTimeoutOrDuplicate,
+	"MISSING":       ErrorCodeMissingInputResponse,
+	"BROWSER_ERROR": ErrorCodeBrowserError,
+}
+
+// Response converts the assessment to a Response so it can be checked with Response.Verify or
+// Policy.Evaluate. An invalid token is reported with the equivalent siteverify error code.
+func (a *Assessment) Response() Response {
+	tp := a.TokenProperties
+
+	r := Response{
+		Success:        tp.Valid,
+		Score:          a.RiskAnalysis.Score,
+		Action:         tp.Action,
+		ChallengeTS:    tp.CreateTime,
+		Hostname:       tp.Hostname,
+		APKPackageName: tp.AndroidPackageName,
+		AssessmentName: a.Name,
+	}
+
+	if !tp.Valid && tp.InvalidReason != "" && tp.InvalidReason != "INVALID_REASON_UNSPECIFIED" {
+		code, ok := invalidReasonCodes[tp.InvalidReason]
+		if !ok {
+			code = ErrorCodeInvalidInputResponse
+		}
+
+		r.ErrorCodes = []string{string(code)}
+	}
+
+	return r
+}
+
+// CreateAssessment creates an assessment of event.
+func (c *EnterpriseClient) CreateAssessment(ctx context.Context, event Event) (*Assessment, error) {
+	req := struct {
+		Event Event `json:"event"`
+	}{Event: event}
+
+	var a Assessment
+	if err := c.do(ctx, "/v1/projects/"+url.PathEscape(c.projectID)+"/assessments", req, &a); err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+// Assess creates an assessment of event and returns it as a Response. Errors are returned by
+// Response.Verify as with SiteVerify.
+func (c *EnterpriseClient) Assess(ctx context.Context, event Event) Response {
+	a, err := c.CreateAssessment(ctx, event)
+	if err != nil {
+		return Response{err: err}
+	}
+
+	return a.Response()
+}
+
+// Annotation is the label given to an assessment by AnnotateAssessment.
+type Annotation string
+
+const (
+	// AnnotationLegitimate means the interaction was legitimate.
+	AnnotationLegitimate Annotation = "LEGITIMATE"
+	// AnnotationFraudulent means the interaction was fraudulent.
+	AnnotationFraudulent Annotation = "FRAUDULENT"
+	// AnnotationPasswordCorrect means the user entered the correct password.
+	AnnotationPasswordCorrect Annotation = "PASSWORD_CORRECT"
+	// AnnotationPasswordIncorrect means the user entered an incorrect password.
+	AnnotationPasswordIncorrect Annotation = "PASSWORD_INCORRECT"
+)
+
+// AnnotationReason is a reason given for an annotation.
+type AnnotationReason string
+
+// Annotation reasons. See the reCAPTCHA Enterprise documentation for their meaning.
+const (
+	ReasonChargeback          AnnotationReason = "CHARGEBACK"
+	ReasonChargebackFraud     AnnotationReason = "CHARGEBACK_FRAUD"
+	ReasonChargebackDispute   AnnotationReason = "CHARGEBACK_DISPUTE"
+	ReasonRefund              AnnotationReason = "REFUND"
+	ReasonRefundFraud         AnnotationReason = "REFUND_FRAUD"
+	ReasonTransactionAccepted AnnotationReason = "TRANSACTION_ACCEPTED"
+	ReasonTransactionDeclined AnnotationReason = "TRANSACTION_DECLINED"
+	ReasonPaymentHeuristics   AnnotationReason = "PAYMENT_HEURISTICS"
+	ReasonInitiatedTwoFactor  AnnotationReason = "INITIATED_TWO_FACTOR"
+	ReasonPassedTwoFactor     AnnotationReason = "PASSED_TWO_FACTOR"
+	ReasonFailedTwoFactor     AnnotationReason = "FAILED_TWO_FACTOR"
+	ReasonCorrectPassword     AnnotationReason = "CORRECT_PASSWORD"
+	ReasonIncorrectPassword   AnnotationReason = "INCORRECT_PASSWORD"
+	ReasonSocialSpam          AnnotationReason = "SOCIAL_SPAM"
+)
+
+// AnnotateRequest is the feedback sent by AnnotateAssessment. At least one of Annotation and
+// Reasons should be set.
+type AnnotateRequest struct {
+	Annotation Annotation         `json:"annotation,omitempty"`
+	Reasons    []AnnotationReason `json:"reasons,omitempty"`
+}
+
+// AnnotateAssessment sends feedback about the assessment with the resource name name, such as
+// Response.AssessmentName or Decision.AssessmentName, to improve future assessments. It can be
+// called long after the assessment was created, for example from a background job once a
+// chargeback is received.
+func (c *EnterpriseClient) AnnotateAssessment(ctx context.Context, name string, req AnnotateRequest) error {
+	if !strings.HasPrefix(name, "projects/") {
+		return fmt.Errorf("recaptchav3: invalid assessment name '%s'", name)
+	}
+
+	var resp struct{}
+
+	return c.do(ctx, "/v1/"+name+":annotate", req, &resp)
+}
+
+// do posts the JSON encoding of in to path and decodes the JSON response into out.
+func (c *EnterpriseClient) do(ctx context.Context, path string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return &TransportError{Op: "encode request", Err: err}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return &TransportError{Err: err}
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Goog-Api-Key", c.apiKey)
+
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return transportFailure(ctx, &TransportError{Err: err})
+	}
+	defer resp.Body.Close()
+
+	b, err := readBody(resp.Body, c.maxResponseSize)
+	if err != nil {
+		return transportFailure(ctx, &TransportError{Op: "read body", Err: err})
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp, bodySnippet(b, c.apiKey))
+	}
+
+	if int64(len(b)) > c.maxResponseSize {
+		return &ResponseTooLargeError{Limit: c.maxResponseSize}
+	}
+
+	if err := json.Unmarshal(b, out); err != nil {
+		return &DecodeError{Err: err, Body: bodySnippet(b, c.apiKey)}
+	}
+
+	return nil
+}
@@ -0,0 +1,302 @@
+package recaptchav3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAssessmentJSON = `{
+  "name": "projects/my-project/assessments/0123456789abcdef",
+  "event": {"token": "token", "siteKey": "site-key", "expectedAction": "login"},
+  "riskAnalysis": {"score": 0.9, "reasons": ["LOW_CONFIDENCE_SCORE"]},
+  "tokenProperties": {
+    "valid": true,
+    "hostname": "example.com",
+    "action": "login",
+    "createTime": "2020-01-24T14:47:44Z"
+  }
+}`
+
+// newEnterpriseServer returns a fake reCAPTCHA Enterprise API that records the last request and
+// responds with body.
+func newEnterpriseServer(t *testing.T, body string, last *http.Request, lastBody *[]byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		*last = *r
+		*lastBody = b
+
+		w.Write([]byte(body))
+	}))
+}
+
+func TestEnterpriseClient_CreateAssessment(t *testing.T) {
+	// arrange
+	var (
+		req  http.Request
+		body []byte
+	)
+
+	ts := newEnterpriseServer(t, testAssessmentJSON, &req, &body)
+	defer ts.Close()
+
+	c := NewEnterpriseClient("my-project", "api-key", WithEnterpriseBaseURL(ts.URL))
+
+	event := Event{Token: "token", SiteKey: "site-key", ExpectedAction: "login", UserIPAddress: "203.0.113.7"}
+
+	// act
+	a, err := c.CreateAssessment(context.Background(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if req.URL.Path != "/v1/projects/my-project/assessments" {
+		t.Errorf("path, want: '/v1/projects/my-project/assessments' got: '%s'", req.URL.Path)
+	}
+
+	if key := req.Header.Get("X-Goog-Api-Key"); key != "api-key" {
+		t.Errorf("X-Goog-Api-Key, want: 'api-key' got: '%s'", key)
+	}
+
+	if req.URL.RawQuery != "" {
+		t.Errorf("query, want: '' got: '%s'", req.URL.RawQuery)
+	}
+
+	const expectedBody = `{"event":{"token":"token","siteKey":"site-key","expectedAction":"login","userIpAddress":"203.0.113.7"}}`
+	if string(body) != expectedBody {
+		t.Errorf("body, want: '%s' got: '%s'", expectedBody, body)
+	}
+
+	if a.Name != "projects/my-project/assessments/0123456789abcdef" {
+		t.Errorf("Name, want: 'projects/my-project/assessments/0123456789abcdef' got: '%s'", a.Name)
+	}
+
+	if !reflect.DeepEqual([]string{"LOW_CONFIDENCE_SCORE"}, a.RiskAnalysis.Reasons) {
+		t.Errorf("Reasons, want: '[LOW_CONFIDENCE_SCORE]' got: '%v'", a.RiskAnalysis.Reasons)
+	}
+}
+
+func TestEnterpriseClient_Assess(t *testing.T) {
+	// arrange
+	var (
+		req  http.Request
+		body []byte
+	)
+
+	ts := newEnterpriseServer(t, testAssessmentJSON, &req, &body)
+	defer ts.Close()
+
+	c := NewEnterpriseClient("my-project", "api-key", WithEnterpriseBaseURL(ts.URL))
+
+	expected := Response{
+		Success:     true,
+		Score:       0.9,
+		Action:      "login",
+		ChallengeTS: time.Date(2020, 1, 24, 14, 47, 44, 0, time.UTC),
+		Hostname:    "example.com",
+	}
+
+	// act
+	actual := c.Assess(context.Background(), Event{Token: "token", SiteKey: "site-key"})
+
+	// assert
+	assertResponseEqual(t, expected, actual)
+
+	policy := &Policy{Actions: map[string]Rule{"login": {MinScore: 0.5, Hostnames: []string{"example.com"}}}}
+	if d := policy.Evaluate("login", actual); !d.Allowed() {
+		t.Errorf("Evaluate, want: allow got: %v '%v'", d.Outcome, d.Reason)
+	}
+}
+
+func TestAssessment_Response_InvalidReason(t *testing.T) {
+	cases := []struct {
+		invalidReason string
+		expected      []string
+	}{
+		{invalidReason: "MALFORMED", expected: []string{"invalid-input-response"}},
+		{invalidReason: "EXPIRED", expected: []string{"timeout-or-duplicate"}},
+		{invalidReason: "DUPE", expected: []string{"timeout-or-duplicate"}},
+		{invalidReason: "MISSING", expected: []string{"missing-input-response"}},
+		{invalidReason: "BROWSER_ERROR", expected: []string{"browser-error"}},
+		{invalidReason: "UNKNOWN_INVALID_REASON", expected: []string{"invalid-input-response"}},
+		{invalidReason: "INVALID_REASON_UNSPECIFIED", expected: nil},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.invalidReason, func(t *testing.T) {
+			// arrange
+			a := &Assessment{TokenProperties: TokenProperties{InvalidReason: tc.invalidReason}}
+
+			// act
+			r := a.Response()
+
+			// assert
+			if !reflect.DeepEqual(tc.expected, r.ErrorCodes) {
+				t.Errorf("want: '%v' got: '%v'", tc.expected, r.ErrorCodes)
+			}
+
+			if r.Verify("", 0, nil) == nil {
+				t.Error("Verify, want: error got: <nil>")
+			}
+		})
+	}
+}
+
+func TestEnterpriseClient_Errors(t *testing.T) {
+	t.Run("HTTPStatus", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": "API key not valid"})
+		}))
+		defer ts.Close()
+
+		c := NewEnterpriseClient("my-project", "secret-api-key", WithEnterpriseBaseURL(ts.URL))
+
+		_, err := c.CreateAssessment(context.Background(), Event{})
+
+		var target *HTTPStatusError
+		if !errors.As(err, &target) || target.StatusCode != http.StatusForbidden {
+			t.Errorf("want: %T 403 got: %T '%v'", target, err, err)
+		}
+
+		if IsUnavailable(err) {
+			t.Error("IsUnavailable, want: false got: true")
+		}
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		c := NewEnterpriseClient("my-project", "secret-api-key", WithEnterpriseBaseURL(ts.URL))
+
+		err := c.Assess(context.Background(), Event{}).Verify("", 0, nil)
+		if !IsUnavailable(err) {
+			t.Errorf("IsUnavailable, want: true got: false '%v'", err)
+		}
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("<html>"))
+		}))
+		defer ts.Close()
+
+		c := NewEnterpriseClient("my-project", "secret-api-key", WithEnterpriseBaseURL(ts.URL))
+
+		_, err := c.CreateAssessment(context.Background(), Event{})
+
+		var target *DecodeError
+		if !errors.As(err, &target) {
+			t.Errorf("want: %T got: %T '%v'", target, err, err)
+		}
+	})
+
+	t.Run("APIKeyRedacted", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("invalid key " + r.Header.Get("X-Goog-Api-Key")))
+		}))
+		defer ts.Close()
+
+		c := NewEnterpriseClient("my-project", "secret-api-key", WithEnterpriseBaseURL(ts.URL))
+
+		_, err := c.CreateAssessment(context.Background(), Event{})
+
+		if err == nil || strings.Contains(err.Error(), "secret-api-key") {
+			t.Errorf("want: API key redacted got: '%v'", err)
+		}
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		ts := newEnterpriseServer(t, testAssessmentJSON, &http.Request{}, new([]byte))
+		defer ts.Close()
+
+		c := NewEnterpriseClient("my-project", "secret-api-key", WithEnterpriseBaseURL(ts.URL), WithEnterpriseMaxResponseSize(16))
+
+		_, err := c.CreateAssessment(context.Background(), Event{})
+
+		if !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("want: '%v' got: '%v'", ErrResponseTooLarge, err)
+		}
+	})
+}
+
+func TestEnterpriseClient_AnnotateAssessment(t *testing.T) {
+	// arrange
+	var (
+		req  http.Request
+		body []byte
+	)
+
+	ts := newEnterpriseServer(t, `{}`, &req, &body)
+	defer ts.Close()
+
+	c := NewEnterpriseClient("my-project", "api-key", WithEnterpriseBaseURL(ts.URL))
+
+	const name = "projects/my-project/assessments/0123456789abcdef"
+
+	// act
+	err := c.AnnotateAssessment(context.Background(), name, AnnotateRequest{
+		Annotation: AnnotationFraudulent,
+		Reasons:    []AnnotationReason{ReasonChargebackFraud},
+	})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.URL.Path != "/v1/projects/my-project/assessments/0123456789abcdef:annotate" {
+		t.Errorf("path, want: '/v1/projects/my-project/assessments/0123456789abcdef:annotate' got: '%s'", req.URL.Path)
+	}
+
+	const expectedBody = `{"annotation":"FRAUDULENT","reasons":["CHARGEBACK_FRAUD"]}`
+	if string(body) != expectedBody {
+		t.Errorf("body, want: '%s' got: '%s'", expectedBody, body)
+	}
+}
+
+func TestEnterpriseClient_AnnotateAssessment_InvalidName(t *testing.T) {
+	// arrange
+	c := NewEnterpriseClient("my-project", "api-key", WithEnterpriseBaseURL("http://127.0.0.1:0"))
+
+	// act
+	err := c.AnnotateAssessment(context.Background(), "", AnnotateRequest{Annotation: AnnotationLegitimate})
+
+	// assert
+	if err == nil {
+		t.Error("want: error got: <nil>")
+	}
+}
+
+func TestAssessment_Response_AssessmentName(t *testing.T) {
+	// arrange
+	var a Assessment
+	if err := json.Unmarshal([]byte(testAssessmentJSON), &a); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	d := (&Policy{Default: &Rule{}}).Evaluate("login", a.Response())
+
+	// assert
+	if d.AssessmentName != "projects/my-project/assessments/0123456789abcdef" {
+		t.Errorf("want: 'projects/my-project/assessments/0123456789abcdef' got: '%s'", d.AssessmentName)
+	}
+}
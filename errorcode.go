@@ -0,0 +1,68 @@
+package recaptchav3
+
+// ErrorCode is an error code returned in the siteverify response.
+//
+// Reference: https://developers.google.com/recaptcha/docs/verify/#error_code_reference
+type ErrorCode string
+
+const (
+	// ErrorCodeMissingInputSecret means the secret parameter is missing.
+	ErrorCodeMissingInputSecret ErrorCode = "missing-input-secret"
+	// ErrorCodeInvalidInputSecret means the secret parameter is invalid or malformed.
+	ErrorCodeInvalidInputSecret ErrorCode = "invalid-input-secret"
+	// ErrorCodeMissingInputResponse means the response parameter is missing.
+	ErrorCodeMissingInputResponse ErrorCode = "missing-input-response"
+	// ErrorCodeInvalidInputResponse means the response parameter is invalid or malformed.
+	ErrorCodeInvalidInputResponse ErrorCode = "invalid-input-response"
+	// ErrorCodeBadRequest means the request is invalid or malformed.
+	ErrorCodeBadRequest ErrorCode = "bad-request"
+	// ErrorCodeTimeoutOrDuplicate means the response is no longer valid: either it is too old or
+	// it has been used previously.
+	ErrorCodeTimeoutOrDuplicate ErrorCode = "timeout-or-duplicate"
+	// ErrorCodeBrowserError means the browser reported an error while solving the challenge.
+	ErrorCodeBrowserError ErrorCode = "browser-error"
+)
+
+// IsConfigError reports whether the code indicates a problem with the server configuration, such as
+// a missing or invalid secret key, rather than with the user's token.
+func (c ErrorCode) IsConfigError() bool {
+	switch c {
+	case ErrorCodeMissingInputSecret, ErrorCodeInvalidInputSecret, ErrorCodeBadRequest:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsClientError reports whether the code indicates a missing or invalid token sent by the client.
+func (c ErrorCode) IsClientError() bool {
+	switch c {
+	case ErrorCodeMissingInputResponse, ErrorCodeInvalidInputResponse, ErrorCodeBrowserError:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsReplay reports whether the code indicates the token expired or was already used.
+func (c ErrorCode) IsReplay() bool {
+	return c == ErrorCodeTimeoutOrDuplicate
+}
+
+// Codes returns the response error codes as ErrorCode values.
+func (r Response) Codes() []ErrorCode {
+	return parseErrorCodes(r.ErrorCodes)
+}
+
+func parseErrorCodes(codes []string) []ErrorCode {
+	if codes == nil {
+		return nil
+	}
+
+	ec := make([]ErrorCode, len(codes))
+	for i, code := range codes {
+		ec[i] = ErrorCode(code)
+	}
+
+	return ec
+}
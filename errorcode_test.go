@@ -0,0 +1,82 @@
+package recaptchav3
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestErrorCode_Classification(t *testing.T) {
+	cases := []struct {
+		code ErrorCode
+
+		config bool
+		client bool
+		replay bool
+	}{
+		{code: ErrorCodeMissingInputSecret, config: true},
+		{code: ErrorCodeInvalidInputSecret, config: true},
+		{code: ErrorCodeBadRequest, config: true},
+		{code: ErrorCodeMissingInputResponse, client: true},
+		{code: ErrorCodeInvalidInputResponse, client: true},
+		{code: ErrorCodeBrowserError, client: true},
+		{code: ErrorCodeTimeoutOrDuplicate, replay: true},
+		{code: "unknown-code"},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(string(tc.code), func(t *testing.T) {
+			if actual := tc.code.IsConfigError(); tc.config != actual {
+				t.Errorf("IsConfigError, want: %v got: %v", tc.config, actual)
+			}
+
+			if actual := tc.code.IsClientError(); tc.client != actual {
+				t.Errorf("IsClientError, want: %v got: %v", tc.client, actual)
+			}
+
+			if actual := tc.code.IsReplay(); tc.replay != actual {
+				t.Errorf("IsReplay, want: %v got: %v", tc.replay, actual)
+			}
+		})
+	}
+}
+
+func TestResponse_Codes(t *testing.T) {
+	// arrange
+	resp := Response{ErrorCodes: []string{"invalid-input-secret", "timeout-or-duplicate"}}
+
+	expected := []ErrorCode{ErrorCodeInvalidInputSecret, ErrorCodeTimeoutOrDuplicate}
+
+	// act
+	actual := resp.Codes()
+
+	// assert
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("want: '%v' got: '%v'", expected, actual)
+	}
+}
+
+func TestErrorCodesError_Classification(t *testing.T) {
+	// arrange
+	resp := Response{ErrorCodes: []string{"invalid-input-response", "timeout-or-duplicate"}}
+
+	// act
+	var target *ErrorCodesError
+	if err := resp.Verify(defaultAction, defaultMinScore, nil); !errors.As(err, &target) {
+		t.Fatalf("want: %T got: %T", target, err)
+	}
+
+	// assert
+	if target.IsConfigError() {
+		t.Error("IsConfigError, want: false got: true")
+	}
+
+	if !target.IsClientError() {
+		t.Error("IsClientError, want: true got: false")
+	}
+
+	if !target.IsReplay() {
+		t.Error("IsReplay, want: true got: false")
+	}
+}
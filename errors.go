@@ -3,8 +3,242 @@ package recaptchav3
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
+var (
+	// ErrNotSuccess is returned by Response.Verify when the response reports success = false
+	// without any error codes.
+	ErrNotSuccess = errors.New("recaptchav3: success = false")
+	// ErrActionMismatch matches any *ActionMismatchError with errors.Is.
+	ErrActionMismatch = errors.New("recaptchav3: action mismatch")
+	// ErrHostnameMismatch matches any *HostnameMismatchError with errors.Is.
+	ErrHostnameMismatch = errors.New("recaptchav3: hostname mismatch")
+	// ErrAPKPackageNameMismatch matches any *APKPackageNameMismatchError with errors.Is.
+	ErrAPKPackageNameMismatch = errors.New("recaptchav3: apk package name mismatch")
+	// ErrChallengeExpired matches a *ChallengeTimeError for a challenge older than the maximum age.
+	ErrChallengeExpired = errors.New("recaptchav3: challenge expired")
+	// ErrChallengeInFuture matches a *ChallengeTimeError for a challenge timestamped in the future.
+	ErrChallengeInFuture = errors.New("recaptchav3: challenge in future")
+	// ErrResponseTooLarge matches any *ResponseTooLargeError with errors.Is.
+	ErrResponseTooLarge = errors.New("recaptchav3: response too large")
+	// ErrFallback matches any *FallbackError with errors.Is.
+	ErrFallback = errors.New("recaptchav3: fall back to next provider")
+)
+
+// ActionMismatchError is returned by Response.Verify when the response action does not equal the
+// expected action.
+type ActionMismatchError struct {
+	// Action is the action in the response.
+	Action string
+	// Expected is the action passed to Verify.
+	Expected string
+}
+
+func (e *ActionMismatchError) Error() string {
+	return fmt.Sprintf("recaptchav3: action '%s' does not equal expected '%s'", e.Action, e.Expected)
+}
+
+// Is reports whether target is ErrActionMismatch.
+func (*ActionMismatchError) Is(target error) bool {
+	return target == ErrActionMismatch
+}
+
+// HostnameMismatchError is returned by Response.Verify when the response hostname is not one of the
+// expected hostnames.
+type HostnameMismatchError struct {
+	// Hostname is the hostname in the response.
+	Hostname string
+	// Expected are the hostnames passed to Verify.
+	Expected []string
+}
+
+func (e *HostnameMismatchError) Error() string {
+	return fmt.Sprintf("recaptchav3: hostname '%s' not in '%s'", e.Hostname, strings.Join(e.Expected, ","))
+}
+
+// Is reports whether target is ErrHostnameMismatch.
+func (*HostnameMismatchError) Is(target error) bool {
+	return target == ErrHostnameMismatch
+}
+
+// APKPackageNameMismatchError is returned by Response.Verify when WithAPKPackageNames is used and
+// the response APK package name is not one of the expected names.
+type APKPackageNameMismatchError struct {
+	// APKPackageName is the APK package name in the response.
+	APKPackageName string
+	// Expected are the names passed to WithAPKPackageNames.
+	Expected []string
+}
+
+func (e *APKPackageNameMismatchError) Error() string {
+	return fmt.Sprintf("recaptchav3: apk_package_name '%s' not in '%s'", e.APKPackageName, strings.Join(e.Expected, ","))
+}
+
+// Is reports whether target is ErrAPKPackageNameMismatch.
+func (*APKPackageNameMismatchError) Is(target error) bool {
+	return target == ErrAPKPackageNameMismatch
+}
+
+// ChallengeTimeError is returned by Response.Verify when WithMaxAge is used and the challenge
+// timestamp is too old or in the future, allowing for clock skew.
+type ChallengeTimeError struct {
+	ChallengeTS time.Time
+	Now         time.Time
+	MaxAge      time.Duration
+	ClockSkew   time.Duration
+}
+
+func (e *ChallengeTimeError) Error() string {
+	if e.future() {
+		return fmt.Sprintf("recaptchav3: challenge_ts '%s' is in the future", e.ChallengeTS.Format(time.RFC3339))
+	}
+
+	return fmt.Sprintf("recaptchav3: challenge_ts '%s' older than '%s'", e.ChallengeTS.Format(time.RFC3339), e.MaxAge)
+}
+
+// Is reports whether target is ErrChallengeInFuture or ErrChallengeExpired, depending on the
+// challenge timestamp.
+func (e *ChallengeTimeError) Is(target error) bool {
+	if e.future() {
+		return target == ErrChallengeInFuture
+	}
+
+	return target == ErrChallengeExpired
+}
+
+func (e *ChallengeTimeError) future() bool {
+	return e.ChallengeTS.After(e.Now.Add(e.ClockSkew))
+}
+
+// ErrorCodesError is returned by Response.Verify when the response contains error codes.
+type ErrorCodesError struct {
+	Codes []ErrorCode
+}
+
+func (e *ErrorCodesError) Error() string {
+	codes := make([]string, len(e.Codes))
+	for i, code := range e.Codes {
+		codes[i] = string(code)
+	}
+
+	return "recaptchav3: " + strings.Join(codes, ",")
+}
+
+// IsConfigError reports whether any of the codes indicate a server configuration problem.
+func (e *ErrorCodesError) IsConfigError() bool {
+	return e.any(ErrorCode.IsConfigError)
+}
+
+// IsClientError reports whether any of the codes indicate a missing or invalid client token.
+func (e *ErrorCodesError) IsClientError() bool {
+	return e.any(ErrorCode.IsClientError)
+}
+
+// IsReplay reports whether any of the codes indicate the token expired or was already used.
+func (e *ErrorCodesError) IsReplay() bool {
+	return e.any(ErrorCode.IsReplay)
+}
+
+func (e *ErrorCodesError) any(f func(ErrorCode) bool) bool {
+	for _, code := range e.Codes {
+		if f(code) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TransportError is returned when the request to the siteverify endpoint could not be made or its
+// response could not be read.
+type TransportError struct {
+	// Op is the failed operation, if more specific than making the request.
+	Op  string
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	if e.Op == "" {
+		return "recaptchav3: " + e.Err.Error()
+	}
+
+	return "recaptchav3: " + e.Op + ": " + e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatusError is returned when the siteverify endpoint responds with a status other than
+// 200 OK.
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+	// Body is the start of the response body with control characters replaced and the secret key
+	// redacted.
+	Body string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("recaptchav3: http: %s, body: '%s'", e.Status, e.Body)
+}
+
+// DecodeError is returned when the siteverify response body is not valid JSON.
+type DecodeError struct {
+	Err error
+	// Body is the start of the response body, as for HTTPStatusError.
+	Body string
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("recaptchav3: error decoding json: %s, body: '%s'", e.Err, e.Body)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ResponseTooLargeError is returned when the response body is larger than the limit set by
+// WithMaxResponseSize.
+type ResponseTooLargeError struct {
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("recaptchav3: response body larger than %d bytes", e.Limit)
+}
+
+// Is reports whether target is ErrResponseTooLarge.
+func (*ResponseTooLargeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+// FallbackError is returned by Response.Verify for a response from Chain.SiteVerify when the
+// provider was unavailable and the chain has another provider to fall back to. The user should be
+// asked to solve the CAPTCHA of the Next provider. It wraps the error from the unavailable provider.
+type FallbackError struct {
+	// Provider is the name of the unavailable provider.
+	Provider string
+	// Next is the name of the provider to fall back to.
+	Next string
+	Err  error
+}
+
+func (e *FallbackError) Error() string {
+	return fmt.Sprintf("recaptchav3: provider '%s' unavailable, fall back to '%s': %v", e.Provider, e.Next, e.Err)
+}
+
+func (e *FallbackError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrFallback.
+func (*FallbackError) Is(target error) bool {
+	return target == ErrFallback
+}
+
 type errBelowMinScore struct {
 	Score    float64
 	MinScore float64
@@ -29,3 +263,32 @@ func (*errBelowMinScore) Is(err error) bool {
 func IsBelowMinScore(err error) bool {
 	return errors.Is(err, &errBelowMinScore{})
 }
+
+type errUnavailable struct {
+	err error
+}
+
+func (e *errUnavailable) Error() string {
+	return e.err.Error()
+}
+
+func (e *errUnavailable) Unwrap() error {
+	return e.err
+}
+
+func (*errUnavailable) Is(err error) bool {
+	var ok bool
+	for !ok && err != nil {
+		_, ok = err.(*errUnavailable)
+		err = errors.Unwrap(err)
+	}
+
+	return ok
+}
+
+// IsUnavailable reports whether the error returned from Response.Verify is due to the siteverify
+// endpoint being unavailable: a network error, a 5xx response, or an open circuit breaker with the
+// FailDegraded policy. Errors caused by the caller's context being done are not reported.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, &errUnavailable{})
+}
@@ -111,3 +111,31 @@ func TestErrBelowMinScore_ErrorsIs(t *testing.T) {
 		})
 	}
 }
+
+func TestIsUnavailable(t *testing.T) {
+	cases := []struct {
+		testName string
+
+		err      error
+		expected bool
+	}{
+		{testName: "Equal", err: &errUnavailable{err: ErrCircuitOpen}, expected: true},
+		{testName: "SingleWrap", err: fmt.Errorf("error: %w", &errUnavailable{err: ErrCircuitOpen}), expected: true},
+		{testName: "CircuitOpen", err: ErrCircuitOpen, expected: false},
+		{testName: "BelowMinScore", err: &errBelowMinScore{}, expected: false},
+		{testName: "NilError", err: nil, expected: false},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			actual := IsUnavailable(tc.err)
+
+			// assert
+			if tc.expected != actual {
+				t.Errorf("want: %v got: %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"time"
 
 	"github.com/blueskysystems/recaptchav3"
 )
@@ -27,3 +29,52 @@ func ExampleSiteVerify() {
 
 	fmt.Println("OK")
 }
+
+func ExampleNewClient() {
+	var (
+		ctx             = context.Background()
+		captchaResponse = "captcha-response"
+		remoteIP        = ""
+	)
+
+	client := recaptchav3.NewClient(
+		recaptchav3.WithSecretKey("secret-key"),
+		recaptchav3.WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+	)
+
+	response := client.SiteVerify(ctx, captchaResponse, remoteIP)
+	if err := response.Verify("homepage", 0.5, nil); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("OK")
+}
+
+func ExampleClient_SiteVerifyV2() {
+	var (
+		ctx             = context.Background()
+		v3Response      = "v3-captcha-response"
+		v2Response      = "v2-captcha-response"
+		remoteIP        = ""
+		hostnames       = []string{"example.com"}
+		challengeMaxAge = 2 * time.Minute
+	)
+
+	client := recaptchav3.NewClient(
+		recaptchav3.WithSecretKey("v3-secret-key"),
+		recaptchav3.WithV2SecretKey("v2-secret-key"),
+	)
+
+	// Verify the v3 token and ask for a v2 checkbox challenge if the score is too low.
+	err := client.SiteVerify(ctx, v3Response, remoteIP).Verify("login", 0.5, hostnames)
+	if recaptchav3.IsBelowMinScore(err) {
+		// Render the v2 checkbox and verify the token it returns.
+		err = client.SiteVerifyV2(ctx, v2Response, remoteIP).VerifyV2(hostnames, recaptchav3.WithMaxAge(challengeMaxAge))
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("OK")
+}
@@ -0,0 +1,149 @@
+package recaptchav3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// WithDeduplication coalesces concurrent verifications of the same secret key, token and remote
+// IP, such as a double-submitted form, into a single request. Every caller receives the Response of
+// that request instead of the later ones failing with timeout-or-duplicate, and the request is only
+// canceled once every caller's context is done. The Response is also returned to identical calls
+// made within grace after the request completes.
+//
+// Responses for requests that did not reach the siteverify endpoint are not kept for the grace
+// period.
+func WithDeduplication(grace time.Duration) Option {
+	return func(c *Client) {
+		c.flights = &flightGroup{grace: grace, calls: make(map[string]*flightCall)}
+	}
+}
+
+// flightGroup deduplicates calls with the same key.
+type flightGroup struct {
+	grace time.Duration
+
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done     chan struct{}
+	response Response
+
+	// waiters is the number of callers that joined the call, less those that gave up.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do calls fn and returns its Response, unless a call with the same key is in flight or completed
+// within the grace period, in which case it returns that call's Response.
+//
+// fn runs with a context that has the values of ctx but is only canceled once every caller waiting
+// for the call has given up, so the first caller canceling, as when a browser aborts the first
+// submission of a double-submitted form, does not fail the others. With a nil ctx fn is called
+// directly.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) Response) Response {
+	if ctx == nil {
+		return fn(ctx)
+	}
+
+	g.mu.Lock()
+	call, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(detachedContext{ctx})
+
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+
+		go g.run(callCtx, key, call, fn)
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.response
+	case <-ctx.Done():
+		g.leave(key, call)
+		return Response{err: &TransportError{Err: ctx.Err()}}
+	}
+}
+
+func (g *flightGroup) run(ctx context.Context, key string, call *flightCall, fn func(ctx context.Context) Response) {
+	defer call.cancel()
+
+	call.response = fn(ctx)
+	close(call.done)
+
+	if g.grace > 0 && consumed(call.response.err) {
+		time.AfterFunc(g.grace, func() { g.forget(key, call) })
+	} else {
+		g.forget(key, call)
+	}
+}
+
+// leave removes a caller that gave up waiting for call. The last one to leave cancels the call and
+// forgets it, so later callers start a new one.
+func (g *flightGroup) leave(key string, call *flightCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	call.waiters--
+
+	select {
+	case <-call.done:
+		return
+	default:
+	}
+
+	if call.waiters == 0 {
+		call.cancel()
+
+		if g.calls[key] == call {
+			delete(g.calls, key)
+		}
+	}
+}
+
+func (g *flightGroup) forget(key string, call *flightCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
+
+// detachedContext has the values of a context but not its deadline or cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// flightKey returns the deduplication key for a request. The values are hashed so secrets and
+// tokens are not kept in memory longer than needed.
+func flightKey(secretKey, captchaResponse, remoteIP string) string {
+	h := sha256.New()
+	h.Write([]byte(secretKey))
+	h.Write([]byte{0})
+	h.Write([]byte(captchaResponse))
+	h.Write([]byte{0})
+	h.Write([]byte(remoteIP))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,254 @@
+package recaptchav3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBlockingServer returns a siteverify server that waits for release before responding with
+// success to the first request for each token and timeout-or-duplicate afterwards, like Google.
+func newBlockingServer(release <-chan struct{}, count *int32) *httptest.Server {
+	var seen sync.Map
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(count, 1)
+		<-release
+
+		if _, dup := seen.LoadOrStore(r.PostFormValue("response"), true); dup {
+			w.Write([]byte(`{"success":false,"error-codes":["timeout-or-duplicate"]}`))
+			return
+		}
+
+		w.Write([]byte(`{"success":true}`))
+	}))
+}
+
+func TestClient_SiteVerify_DeduplicatesConcurrent(t *testing.T) {
+	// arrange
+	var count int32
+
+	release := make(chan struct{})
+
+	ts := newBlockingServer(release, &count)
+	defer ts.Close()
+
+	c := NewClient(
+		WithURL(ts.URL),
+		WithDeduplication(0),
+		WithTokenStore(NewMemoryTokenStore(), time.Minute),
+	)
+
+	const callers = 5
+
+	var wg sync.WaitGroup
+
+	errs := make([]error, callers)
+
+	// act
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.SiteVerify(context.Background(), "token", "127.0.0.1").Verify("", 0, nil)
+		}(i)
+	}
+
+	for atomic.LoadInt32(&count) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// assert
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("caller %d, want: <nil> got: '%v'", i, err)
+		}
+	}
+
+	if actual := atomic.LoadInt32(&count); actual != 1 {
+		t.Errorf("requests, want: 1 got: %d", actual)
+	}
+}
+
+func TestClient_SiteVerify_DeduplicationGrace(t *testing.T) {
+	cases := []struct {
+		testName string
+
+		grace    time.Duration
+		remoteIP string
+		requests int32
+	}{
+		{testName: "WithinGrace", grace: time.Minute, remoteIP: "127.0.0.1", requests: 1},
+		{testName: "NoGrace", grace: 0, remoteIP: "127.0.0.1", requests: 2},
+		{testName: "DifferentRemoteIP", grace: time.Minute, remoteIP: "127.0.0.2", requests: 2},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// arrange
+			var count int32
+
+			release := make(chan struct{})
+			close(release)
+
+			ts := newBlockingServer(release, &count)
+			defer ts.Close()
+
+			client := NewClient(WithURL(ts.URL), WithDeduplication(tc.grace))
+
+			// act
+			client.SiteVerify(context.Background(), "token", "127.0.0.1")
+			client.SiteVerify(context.Background(), "token", tc.remoteIP)
+
+			// assert
+			if actual := atomic.LoadInt32(&count); tc.requests != actual {
+				t.Errorf("requests, want: %d got: %d", tc.requests, actual)
+			}
+		})
+	}
+}
+
+func TestFlightGroup_WaiterContextCanceled(t *testing.T) {
+	// arrange
+	g := &flightGroup{calls: make(map[string]*flightCall)}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go g.do(context.Background(), "key", func(context.Context) Response {
+		close(started)
+		<-release
+		return Response{Success: true}
+	})
+	defer close(release)
+
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// act
+	response := g.do(ctx, "key", func(context.Context) Response {
+		t.Error("fn called for waiter")
+		return Response{}
+	})
+
+	// assert
+	if response.err == nil {
+		t.Error("want: context canceled got: <nil>")
+	}
+}
+
+func TestFlightGroup_FirstCallerContextCanceled(t *testing.T) {
+	// arrange
+	g := &flightGroup{calls: make(map[string]*flightCall)}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	first, cancel := context.WithCancel(context.Background())
+
+	firstResponse := make(chan Response, 1)
+	go func() {
+		firstResponse <- g.do(first, "key", func(ctx context.Context) Response {
+			close(started)
+
+			select {
+			case <-release:
+				return Response{Success: true}
+			case <-ctx.Done():
+				return Response{err: &TransportError{Err: ctx.Err()}}
+			}
+		})
+	}()
+
+	<-started
+
+	waiterResponse := make(chan Response, 1)
+	go func() {
+		waiterResponse <- g.do(context.Background(), "key", func(context.Context) Response {
+			t.Error("fn called for waiter")
+			return Response{}
+		})
+	}()
+
+	for waiters(g, "key") < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// act
+	cancel()
+	canceled := <-firstResponse
+
+	close(release)
+	response := <-waiterResponse
+
+	// assert
+	if !errors.Is(canceled.err, context.Canceled) {
+		t.Errorf("first, want: '%v' got: '%v'", context.Canceled, canceled.err)
+	}
+
+	if !response.Success || response.err != nil {
+		t.Errorf("waiter, want: success got: %v '%v'", response.Success, response.err)
+	}
+}
+
+func TestFlightGroup_AllCallersContextCanceled(t *testing.T) {
+	// arrange
+	g := &flightGroup{calls: make(map[string]*flightCall)}
+
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	// act
+	g.do(ctx, "key", func(ctx context.Context) Response {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return Response{}
+	})
+
+	// assert
+	select {
+	case err := <-stopped:
+		if err != context.Canceled {
+			t.Errorf("want: '%v' got: '%v'", context.Canceled, err)
+		}
+	case <-time.After(time.Second):
+		t.Error("want: call canceled got: still running")
+	}
+
+	if w := waiters(g, "key"); w != 0 {
+		t.Errorf("calls, want: none got: %d waiters", w)
+	}
+}
+
+// waiters returns the number of callers waiting for the call with key, or 0 if there is none.
+func waiters(g *flightGroup, key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if call, ok := g.calls[key]; ok {
+		return call.waiters
+	}
+
+	return 0
+}
@@ -0,0 +1,106 @@
+package recaptchav3
+
+import (
+	"net"
+	"strings"
+)
+
+// HostnameMatcher matches the hostname of a response against a list of patterns. Matching is case
+// insensitive and internationalized domain names are compared in their punycode form, so patterns
+// and hostnames may be given in either Unicode or ASCII form.
+//
+// Patterns take one of three forms:
+//
+//	example.com     matches example.com only
+//	*.example.com   matches any subdomain of example.com, such as a.example.com or a.b.example.com
+//	.example.com    matches example.com and any subdomain of it
+//
+// Patterns without a hostname, such as "", "." or "*.", match nothing.
+type HostnameMatcher struct {
+	Patterns []string
+	// StripPort removes a port, as in "example.com:8080", from the hostname before matching.
+	StripPort bool
+}
+
+// Match reports whether hostname matches any of the patterns.
+func (m HostnameMatcher) Match(hostname string) bool {
+	if m.StripPort {
+		if host, _, err := net.SplitHostPort(hostname); err == nil {
+			hostname = host
+		}
+	}
+
+	hostname, ok := normalizeHostname(hostname)
+	if !ok || hostname == "" {
+		return false
+	}
+
+	for _, pattern := range m.Patterns {
+		if matchHostname(pattern, hostname) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchHostname(pattern, hostname string) bool {
+	host, kind, ok := parsePattern(pattern)
+	if !ok {
+		return false
+	}
+
+	switch kind {
+	case patternWildcard:
+		return strings.HasSuffix(hostname, "."+host)
+	case patternSuffix:
+		return hostname == host || strings.HasSuffix(hostname, "."+host)
+	default:
+		return hostname == host
+	}
+}
+
+type patternKind int
+
+const (
+	patternExact patternKind = iota
+	patternWildcard
+	patternSuffix
+)
+
+// parsePattern returns the normalized hostname of pattern without its "*." or "." prefix, and the
+// form of the pattern. It reports false for patterns that cannot match a hostname, such as "", "."
+// or "*.", so they never match everything.
+func parsePattern(pattern string) (string, patternKind, bool) {
+	kind := patternExact
+
+	switch {
+	case strings.HasPrefix(pattern, "*."):
+		pattern, kind = pattern[2:], patternWildcard
+	case strings.HasPrefix(pattern, "."):
+		pattern, kind = pattern[1:], patternSuffix
+	}
+
+	host, ok := normalizeHostname(pattern)
+	if !ok || host == "" {
+		return "", kind, false
+	}
+
+	for _, label := range strings.Split(host, ".") {
+		if label == "" {
+			return "", kind, false
+		}
+	}
+
+	return host, kind, true
+}
+
+// normalizeHostname lowercases hostname, encodes it with punycode and removes a trailing dot.
+func normalizeHostname(hostname string) (string, bool) {
+	hostname, err := toASCII(strings.TrimSuffix(hostname, "."))
+	if err != nil {
+		return "", false
+	}
+
+	return hostname, true
+}
@@ -0,0 +1,86 @@
+package recaptchav3
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHostnameMatcher_Match(t *testing.T) {
+	cases := []struct {
+		testName string
+
+		patterns  []string
+		stripPort bool
+		hostname  string
+		expected  bool
+	}{
+		{testName: "Exact", patterns: []string{"example.com"}, hostname: "example.com", expected: true},
+		{testName: "ExactMismatch", patterns: []string{"example.com"}, hostname: "www.example.com", expected: false},
+		{testName: "CaseInsensitive", patterns: []string{"Example.COM"}, hostname: "EXAMPLE.com", expected: true},
+		{testName: "TrailingDot", patterns: []string{"example.com"}, hostname: "example.com.", expected: true},
+		{testName: "Wildcard", patterns: []string{"*.example.com"}, hostname: "tenant.example.com", expected: true},
+		{testName: "WildcardDeep", patterns: []string{"*.example.com"}, hostname: "a.b.example.com", expected: true},
+		{testName: "WildcardApex", patterns: []string{"*.example.com"}, hostname: "example.com", expected: false},
+		{testName: "WildcardSuffixOnly", patterns: []string{"*.example.com"}, hostname: "badexample.com", expected: false},
+		{testName: "Suffix", patterns: []string{".example.com"}, hostname: "tenant.example.com", expected: true},
+		{testName: "SuffixApex", patterns: []string{".example.com"}, hostname: "example.com", expected: true},
+		{testName: "SuffixMismatch", patterns: []string{".example.com"}, hostname: "example.org", expected: false},
+		{testName: "IDNPattern", patterns: []string{"*.münchen.de"}, hostname: "www.xn--mnchen-3ya.de", expected: true},
+		{testName: "IDNHostname", patterns: []string{"xn--mnchen-3ya.de"}, hostname: "MÜNCHEN.de", expected: true},
+		{testName: "Port", patterns: []string{"example.com"}, hostname: "example.com:8080", expected: false},
+		{testName: "StripPort", patterns: []string{"example.com"}, stripPort: true, hostname: "example.com:8080", expected: true},
+		{testName: "StripPortIPv6", patterns: []string{"::1"}, stripPort: true, hostname: "[::1]:8080", expected: true},
+		{testName: "Empty", patterns: []string{"example.com"}, hostname: "", expected: false},
+		{testName: "NoPatterns", patterns: nil, hostname: "example.com", expected: false},
+		{testName: "Dot", patterns: []string{"."}, hostname: "example.com", expected: false},
+		{testName: "BareWildcard", patterns: []string{"*."}, hostname: "evil.com", expected: false},
+		{testName: "DoubleDot", patterns: []string{".."}, hostname: "example.com", expected: false},
+		{testName: "EmptyLabel", patterns: []string{"*..example.com"}, hostname: "a..example.com", expected: false},
+		{testName: "EmptyPattern", patterns: []string{""}, hostname: "example.com", expected: false},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// arrange
+			m := HostnameMatcher{Patterns: tc.patterns, StripPort: tc.stripPort}
+
+			// act
+			actual := m.Match(tc.hostname)
+
+			// assert
+			if tc.expected != actual {
+				t.Errorf("want: %v got: %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestResponse_Verify_HostnamePatterns(t *testing.T) {
+	// arrange
+	resp := Response{Success: true, Action: defaultAction, Hostname: "tenant.example.com", Score: 1.0}
+
+	// act/assert
+	if err := resp.Verify(defaultAction, defaultMinScore, []string{"*.example.com"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResponse_Verify_WithHostnameMatcher(t *testing.T) {
+	// arrange
+	resp := Response{Success: true, Action: defaultAction, Hostname: "example.com:8443", Score: 1.0}
+
+	m := HostnameMatcher{Patterns: []string{"example.com"}, StripPort: true}
+
+	// act
+	err := resp.Verify(defaultAction, defaultMinScore, nil, WithHostnameMatcher(m))
+
+	// assert
+	if err != nil {
+		t.Error(err)
+	}
+
+	if err := resp.Verify(defaultAction, defaultMinScore, []string{"example.com"}); !errors.Is(err, ErrHostnameMismatch) {
+		t.Errorf("without matcher, want: '%v' got: '%v'", ErrHostnameMismatch, err)
+	}
+}
@@ -0,0 +1,262 @@
+package recaptchav3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// DefaultTokenField is the form field the reCAPTCHA JavaScript API uses for the token.
+const DefaultTokenField = "g-recaptcha-response"
+
+// maxJSONTokenBody is the maximum number of bytes of a JSON body read to find the token.
+const maxJSONTokenBody = 1 << 20
+
+// TokenSource extracts the reCAPTCHA token from a request. It returns an empty string if the
+// request has no token.
+type TokenSource func(r *http.Request) string
+
+// TokenFromHeader returns a TokenSource that reads the token from the named header.
+func TokenFromHeader(name string) TokenSource {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// TokenFromForm returns a TokenSource that reads the token from the named field of a
+// application/x-www-form-urlencoded or multipart/form-data body.
+func TokenFromForm(field string) TokenSource {
+	return func(r *http.Request) string {
+		return r.PostFormValue(field)
+	}
+}
+
+// TokenFromQuery returns a TokenSource that reads the token from the named URL query parameter.
+func TokenFromQuery(param string) TokenSource {
+	return func(r *http.Request) string {
+		return r.URL.Query().Get(param)
+	}
+}
+
+// TokenFromJSON returns a TokenSource that reads the token from the named top level string field
+// of a JSON object body. The body is restored so the next handler can read it again.
+func TokenFromJSON(field string) TokenSource {
+	return func(r *http.Request) string {
+		if r.Body == nil || r.Body == http.NoBody {
+			return ""
+		}
+
+		b, err := ioutil.ReadAll(io.LimitReader(r.Body, maxJSONTokenBody))
+		r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(b), r.Body), Closer: r.Body}
+
+		if err != nil {
+			return ""
+		}
+
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return ""
+		}
+
+		var token string
+		if err := json.Unmarshal(obj[field], &token); err != nil {
+			return ""
+		}
+
+		return token
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// RejectHandler writes the response for a request that was not allowed. The decision outcome is
+// either OutcomeDeny or OutcomeChallenge.
+type RejectHandler func(w http.ResponseWriter, r *http.Request, d Decision)
+
+// DefaultRejectHandler responds with 503 Service Unavailable if the siteverify endpoint was
+// unavailable, 429 Too Many Requests if the request was rate limited and 403 Forbidden otherwise.
+func DefaultRejectHandler(w http.ResponseWriter, r *http.Request, d Decision) {
+	if errors.Is(d.Reason, ErrRateLimited) {
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
+	if IsUnavailable(d.Reason) {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
+
+// MiddlewareOption configures Middleware.
+type MiddlewareOption func(*middleware)
+
+type middleware struct {
+	client  *Client
+	policy  *Policy
+	sources []TokenSource
+	action  func(r *http.Request) string
+	site    func(r *http.Request) string
+	reject  RejectHandler
+}
+
+// WithClient sets the Client used to verify tokens. It is required.
+func WithClient(c *Client) MiddlewareOption {
+	return func(m *middleware) {
+		m.client = c
+	}
+}
+
+// WithTokenSources sets where the token is read from. The sources are tried in order and the first
+// non-empty token is used. The default is TokenFromForm(DefaultTokenField).
+func WithTokenSources(sources ...TokenSource) MiddlewareOption {
+	return func(m *middleware) {
+		m.sources = sources
+	}
+}
+
+// WithActions maps request URL paths to the expected action names.
+func WithActions(routes map[string]string) MiddlewareOption {
+	return func(m *middleware) {
+		m.action = func(r *http.Request) string {
+			return routes[r.URL.Path]
+		}
+	}
+}
+
+// WithActionFunc sets a function returning the expected action for a request. It overrides
+// WithActions.
+func WithActionFunc(f func(r *http.Request) string) MiddlewareOption {
+	return func(m *middleware) {
+		m.action = f
+	}
+}
+
+// WithSiteFunc sets a function returning the ID of the Site, registered with the Client by
+// WithSites, whose secret key and policy are used for a request. The default, if the Client has
+// sites, selects the site by the request Host with Client.SiteForHost.
+func WithSiteFunc(f func(r *http.Request) string) MiddlewareOption {
+	return func(m *middleware) {
+		m.site = f
+	}
+}
+
+// WithRejectHandler sets the handler called for requests that are not allowed. The default is
+// DefaultRejectHandler.
+func WithRejectHandler(h RejectHandler) MiddlewareOption {
+	return func(m *middleware) {
+		m.reject = h
+	}
+}
+
+// Middleware returns HTTP middleware that reads the reCAPTCHA token from each request, verifies it
+// with Client.SiteVerifyRequest using the Client set by WithClient, and evaluates the response
+// against policy for the action set by WithActions or WithActionFunc. Allowed requests are passed
+// to the next handler; all others are passed to the reject handler. In both cases the Response and
+// Decision are stored in the request context, see ResponseFromContext and DecisionFromContext.
+//
+// If the Client has sites, each request is verified with the secret key of its site and evaluated
+// with Site.Evaluate, using policy for sites without their own. Requests for which no site is found
+// are denied with ErrUnknownSite. See WithSiteFunc.
+//
+// Middleware panics if WithClient is not given, or policy is nil and the Client has no sites.
+func Middleware(policy *Policy, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	m := &middleware{
+		policy:  policy,
+		sources: []TokenSource{TokenFromForm(DefaultTokenField)},
+		action:  func(*http.Request) string { return "" },
+		reject:  DefaultRejectHandler,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.client == nil {
+		panic("recaptchav3: Middleware requires WithClient")
+	}
+
+	if m.policy == nil && len(m.client.sites) == 0 {
+		panic("recaptchav3: Middleware requires a policy")
+	}
+
+	if m.site == nil && len(m.client.sites) != 0 {
+		m.site = func(r *http.Request) string {
+			site, _ := m.client.SiteForHost(r.Host)
+			return site.ID
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response, d := m.verify(r)
+
+			ctx := context.WithValue(r.Context(), responseContextKey{}, response)
+			ctx = context.WithValue(ctx, decisionContextKey{}, d)
+			r = r.WithContext(ctx)
+
+			if !d.Allowed() {
+				m.reject(w, r, d)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (m *middleware) verify(r *http.Request) (Response, Decision) {
+	action := m.action(r)
+
+	var token string
+	for _, source := range m.sources {
+		if token = source(r); token != "" {
+			break
+		}
+	}
+
+	var site Site
+	if m.site != nil {
+		var ok bool
+		if site, ok = m.client.Site(m.site(r)); !ok {
+			return Response{err: ErrUnknownSite}, Decision{Action: action, Reason: ErrUnknownSite}
+		}
+
+		if site.Policy == nil {
+			site.Policy = m.policy
+		}
+	}
+
+	if m.site == nil {
+		response := m.client.SiteVerifyRequest(r, token)
+		return response, m.policy.Evaluate(action, response)
+	}
+
+	response := m.client.SiteVerifySiteRequest(r, site.ID, token)
+
+	return response, site.Evaluate(action, response)
+}
+
+type responseContextKey struct{}
+
+type decisionContextKey struct{}
+
+// ResponseFromContext returns the Response stored in ctx by Middleware.
+func ResponseFromContext(ctx context.Context) (Response, bool) {
+	response, ok := ctx.Value(responseContextKey{}).(Response)
+	return response, ok
+}
+
+// DecisionFromContext returns the Decision stored in ctx by Middleware.
+func DecisionFromContext(ctx context.Context) (Decision, bool) {
+	d, ok := ctx.Value(decisionContextKey{}).(Decision)
+	return d, ok
+}
@@ -0,0 +1,391 @@
+package recaptchav3
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// newScoreServer returns a siteverify server that responds with success for the action "login"
+// and uses the token as the score, so "0.9" scores 0.9.
+func newScoreServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"success":true,"action":"login","score":%s}`, r.PostFormValue("response"))
+	}))
+}
+
+func newTestMiddleware(t *testing.T, ts *httptest.Server, opts ...MiddlewareOption) (http.Handler, *bool) {
+	t.Helper()
+
+	policy := &Policy{
+		Actions: map[string]Rule{
+			"login": {MinScore: 0.7, ChallengeScore: 0.3},
+		},
+	}
+
+	called := new(bool)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*called = true
+
+		response, ok := ResponseFromContext(r.Context())
+		if !ok {
+			t.Error("ResponseFromContext, want: true got: false")
+		}
+
+		if _, ok := DecisionFromContext(r.Context()); !ok {
+			t.Error("DecisionFromContext, want: true got: false")
+		}
+
+		fmt.Fprintf(w, "score %g", response.Score)
+	})
+
+	opts = append([]MiddlewareOption{
+		WithClient(NewClient(WithURL(ts.URL), WithSecretKey("secret"))),
+		WithActions(map[string]string{"/login": "login"}),
+	}, opts...)
+
+	return Middleware(policy, opts...)(next), called
+}
+
+func newFormRequest(path, token string) *http.Request {
+	form := url.Values{DefaultTokenField: {token}}
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return req
+}
+
+func TestMiddleware_Allow(t *testing.T) {
+	// arrange
+	ts := newScoreServer()
+	defer ts.Close()
+
+	h, called := newTestMiddleware(t, ts)
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, newFormRequest("/login", "0.9"))
+
+	// assert
+	if !*called {
+		t.Error("next called, want: true got: false")
+	}
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "score 0.9" {
+		t.Errorf("want: 200 'score 0.9' got: %d '%s'", rec.Code, rec.Body.String())
+	}
+}
+
+func TestMiddleware_Deny(t *testing.T) {
+	// arrange
+	ts := newScoreServer()
+	defer ts.Close()
+
+	h, called := newTestMiddleware(t, ts)
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, newFormRequest("/login", "0.1"))
+
+	// assert
+	if *called {
+		t.Error("next called, want: false got: true")
+	}
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("want: 403 got: %d", rec.Code)
+	}
+}
+
+func TestMiddleware_UnmappedRoute(t *testing.T) {
+	// arrange
+	ts := newScoreServer()
+	defer ts.Close()
+
+	h, called := newTestMiddleware(t, ts)
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, newFormRequest("/other", "0.9"))
+
+	// assert
+	if *called {
+		t.Error("next called, want: false got: true")
+	}
+}
+
+func TestMiddleware_RejectHandlerChallenge(t *testing.T) {
+	// arrange
+	ts := newScoreServer()
+	defer ts.Close()
+
+	var decision Decision
+
+	h, _ := newTestMiddleware(t, ts, WithRejectHandler(func(w http.ResponseWriter, r *http.Request, d Decision) {
+		decision = d
+
+		if _, ok := ResponseFromContext(r.Context()); !ok {
+			t.Error("ResponseFromContext, want: true got: false")
+		}
+
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, newFormRequest("/login", "0.5"))
+
+	// assert
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("want: 401 got: %d", rec.Code)
+	}
+
+	if !decision.Challenged() {
+		t.Errorf("Outcome, want: %v got: %v", OutcomeChallenge, decision.Outcome)
+	}
+}
+
+func TestMiddleware_MissingToken(t *testing.T) {
+	// arrange
+	var requests int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+
+	var decision Decision
+
+	h, _ := newTestMiddleware(t, ts, WithRejectHandler(func(w http.ResponseWriter, r *http.Request, d Decision) {
+		decision = d
+	}))
+
+	// act
+	h.ServeHTTP(httptest.NewRecorder(), newFormRequest("/login", ""))
+
+	// assert
+	if requests != 0 {
+		t.Errorf("requests, want: 0 got: %d", requests)
+	}
+
+	var target *ErrorCodesError
+	if !errors.As(decision.Reason, &target) || !target.IsClientError() {
+		t.Errorf("Reason, want: missing-input-response got: '%v'", decision.Reason)
+	}
+}
+
+func TestMiddleware_Unavailable(t *testing.T) {
+	// arrange
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	h, _ := newTestMiddleware(t, ts)
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, newFormRequest("/login", "0.9"))
+
+	// assert
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("want: 503 got: %d", rec.Code)
+	}
+}
+
+func TestMiddleware_TokenSources(t *testing.T) {
+	ts := newScoreServer()
+	defer ts.Close()
+
+	cases := []struct {
+		testName string
+
+		source  TokenSource
+		request func() *http.Request
+	}{
+		{
+			testName: "Header",
+			source:   TokenFromHeader("X-Recaptcha-Token"),
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/login", nil)
+				req.Header.Set("X-Recaptcha-Token", "0.9")
+				return req
+			},
+		},
+		{
+			testName: "Query",
+			source:   TokenFromQuery("token"),
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/login?token=0.9", nil)
+			},
+		},
+		{
+			testName: "JSON",
+			source:   TokenFromJSON("token"),
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"token":"0.9","user":"a"}`))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// arrange
+			h, called := newTestMiddleware(t, ts, WithTokenSources(TokenFromForm(DefaultTokenField), tc.source))
+			rec := httptest.NewRecorder()
+
+			// act
+			h.ServeHTTP(rec, tc.request())
+
+			// assert
+			if !*called {
+				t.Errorf("next called, want: true got: false, status: %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestTokenFromJSON_RestoresBody(t *testing.T) {
+	// arrange
+	const body = `{"token":"abc","user":"a"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+
+	// act
+	token := TokenFromJSON("token")(req)
+
+	// assert
+	if token != "abc" {
+		t.Errorf("token, want: 'abc' got: '%s'", token)
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != body {
+		t.Errorf("body, want: '%s' got: '%s'", body, b)
+	}
+}
+
+func TestMiddleware_Sites(t *testing.T) {
+	// arrange
+	var secrets []string
+
+	ts := newSiteServer(&secrets)
+	defer ts.Close()
+
+	sites := append([]Site{}, testSites...)
+	sites[1].Policy = &Policy{Actions: map[string]Rule{"login": {MinScore: 0.95}}}
+
+	client := NewClient(WithURL(ts.URL), WithSites(sites...))
+	policy := &Policy{Actions: map[string]Rule{"login": {MinScore: 0.5}}}
+
+	var decision Decision
+
+	h := Middleware(policy,
+		WithClient(client),
+		WithActions(map[string]string{"/login": "login"}),
+		WithRejectHandler(func(w http.ResponseWriter, r *http.Request, d Decision) {
+			decision = d
+			w.WriteHeader(http.StatusForbidden)
+		}),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	cases := []struct {
+		testName string
+
+		host     string
+		token    string
+		expected int
+		secret   string
+		reason   error
+	}{
+		{testName: "SiteA", host: "a.example.com", token: "a.example.com", expected: http.StatusOK, secret: "a-secret"},
+		{testName: "SiteAHostnameMismatch", host: "a.example.com", token: "b.example.com", expected: http.StatusForbidden, secret: "a-secret"},
+		{testName: "SiteBPolicy", host: "www.b.example.com", token: "www.b.example.com", expected: http.StatusForbidden, secret: "b-secret"},
+		{testName: "UnknownSite", host: "c.example.com", token: "c.example.com", expected: http.StatusForbidden, reason: ErrUnknownSite},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// arrange
+			secrets = nil
+
+			req := newFormRequest("/login", tc.token)
+			req.Host = tc.host
+
+			rec := httptest.NewRecorder()
+
+			// act
+			h.ServeHTTP(rec, req)
+
+			// assert
+			if rec.Code != tc.expected {
+				t.Errorf("want: %d got: %d '%v'", tc.expected, rec.Code, decision.Reason)
+			}
+
+			if tc.secret != "" && (len(secrets) != 1 || secrets[0] != tc.secret) {
+				t.Errorf("secrets, want: [%s] got: %v", tc.secret, secrets)
+			}
+
+			if tc.reason != nil && !errors.Is(decision.Reason, tc.reason) {
+				t.Errorf("Reason, want: '%v' got: '%v'", tc.reason, decision.Reason)
+			}
+		})
+	}
+}
+
+func TestMiddleware_SiteFunc(t *testing.T) {
+	// arrange
+	var secrets []string
+
+	ts := newSiteServer(&secrets)
+	defer ts.Close()
+
+	sites := append([]Site{}, testSites...)
+	for i := range sites {
+		sites[i].Policy = &Policy{Actions: map[string]Rule{"login": {MinScore: 0.5}}}
+	}
+
+	h := Middleware(nil,
+		WithClient(NewClient(WithURL(ts.URL), WithSites(sites...))),
+		WithActions(map[string]string{"/login": "login"}),
+		WithSiteFunc(func(r *http.Request) string { return r.Header.Get("X-Site") }),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := newFormRequest("/login", "a.example.com")
+	req.Header.Set("X-Site", "a")
+
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, req)
+
+	// assert
+	if rec.Code != http.StatusOK || len(secrets) != 1 || secrets[0] != "a-secret" {
+		t.Errorf("want: 200 [a-secret] got: %d %v", rec.Code, secrets)
+	}
+}
+
+func TestDefaultRejectHandler_RateLimited(t *testing.T) {
+	// arrange
+	rec := httptest.NewRecorder()
+
+	// act
+	DefaultRejectHandler(rec, httptest.NewRequest(http.MethodPost, "/login", nil), Decision{Reason: &RateLimitError{}})
+
+	// assert
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("want: 429 got: %d", rec.Code)
+	}
+}
@@ -0,0 +1,258 @@
+package recaptchav3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoRule is the Decision reason when a Policy has no rule for the action and no default rule.
+var ErrNoRule = errors.New("recaptchav3: no rule for action")
+
+// Rule declares how responses for an action are verified.
+//
+// Scores divide requests into three bands: at or above MinScore the request is allowed, at or above
+// ChallengeScore it should be challenged with a second factor such as an email code or a reCAPTCHA
+// v2 checkbox, and below that it is denied.
+type Rule struct {
+	// MinScore is the minimum score (0.0 - 1.0) to allow the request.
+	MinScore float64 `json:"min_score"`
+	// ChallengeScore is the minimum score to challenge rather than deny a request scoring below
+	// MinScore. If zero there is no challenge band and scores below MinScore are denied.
+	ChallengeScore float64 `json:"challenge_score,omitempty"`
+	// Hostnames are the allowed hostnames, which may use the patterns described in HostnameMatcher.
+	// If empty the hostname is not checked.
+	Hostnames []string `json:"hostnames,omitempty"`
+	// APKPackageNames are the allowed Android app package names. Responses from Android apps are
+	// checked against these instead of Hostnames.
+	APKPackageNames []string `json:"apk_package_names,omitempty"`
+	// MaxAge is the maximum time since the challenge was loaded. Zero means no limit.
+	MaxAge Duration `json:"max_age,omitempty"`
+}
+
+// Policy declares verification rules per action name. It can be built in Go or loaded from JSON
+// with ParsePolicy:
+//
+//	{
+//	  "actions": {
+//	    "login":    {"min_score": 0.7, "challenge_score": 0.3, "hostnames": ["example.com"], "max_age": "2m"},
+//	    "homepage": {"min_score": 0.3}
+//	  },
+//	  "default": {"min_score": 0.5}
+//	}
+type Policy struct {
+	// Actions maps action names to rules.
+	Actions map[string]Rule `json:"actions"`
+	// Default is the rule for actions not in Actions. If nil, such actions are denied.
+	Default *Rule `json:"default,omitempty"`
+	// ClockSkew is the tolerance applied to challenge timestamps when checking MaxAge.
+	ClockSkew Duration `json:"clock_skew,omitempty"`
+	// Clock returns the current time when checking MaxAge. If nil, time.Now is used.
+	Clock func() time.Time `json:"-"`
+}
+
+// ParsePolicy parses a JSON encoded Policy and checks its rules are valid.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("recaptchav3: parse policy: %w", err)
+	}
+
+	for action, rule := range p.Actions {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("recaptchav3: parse policy: action '%s': %w", action, err)
+		}
+	}
+
+	if p.Default != nil {
+		if err := p.Default.validate(); err != nil {
+			return nil, fmt.Errorf("recaptchav3: parse policy: default: %w", err)
+		}
+	}
+
+	if p.ClockSkew < 0 {
+		return nil, fmt.Errorf("recaptchav3: parse policy: clock_skew '%s' is negative", time.Duration(p.ClockSkew))
+	}
+
+	return &p, nil
+}
+
+func (r Rule) validate() error {
+	if r.MinScore < 0 || r.MinScore > 1 {
+		return fmt.Errorf("min_score '%g' not in range 0.0 - 1.0", r.MinScore)
+	}
+
+	if r.ChallengeScore < 0 || r.ChallengeScore > r.MinScore {
+		return fmt.Errorf("challenge_score '%g' not in range 0.0 - %g", r.ChallengeScore, r.MinScore)
+	}
+
+	if r.MaxAge < 0 {
+		return fmt.Errorf("max_age '%s' is negative", time.Duration(r.MaxAge))
+	}
+
+	for _, pattern := range r.Hostnames {
+		if _, _, ok := parsePattern(pattern); !ok {
+			return fmt.Errorf("hostname pattern '%s' is invalid", pattern)
+		}
+	}
+
+	return nil
+}
+
+// Rule returns the rule for action, falling back to the default rule. It reports false if there
+// is neither.
+func (p *Policy) Rule(action string) (Rule, bool) {
+	if rule, ok := p.Actions[action]; ok {
+		return rule, true
+	}
+
+	if p.Default != nil {
+		return *p.Default, true
+	}
+
+	return Rule{}, false
+}
+
+// Evaluate verifies r against the rule for action and returns the decision. The action is the
+// action expected by the caller, not the action in the response; a response for any other action
+// is denied.
+func (p *Policy) Evaluate(action string, r Response) Decision {
+	return p.evaluate(action, r, nil)
+}
+
+// evaluate is Evaluate checking the response hostname against hostnames if the rule has none.
+func (p *Policy) evaluate(action string, r Response, hostnames []string) Decision {
+	d := Decision{Action: action, Score: r.Score, Provider: r.Provider, AssessmentName: r.AssessmentName}
+
+	rule, ok := p.Rule(action)
+	if !ok {
+		d.Reason = ErrNoRule
+		return d
+	}
+
+	d.Rule = rule
+
+	if len(rule.Hostnames) != 0 {
+		hostnames = rule.Hostnames
+	}
+
+	minScore := rule.MinScore
+	if rule.ChallengeScore > 0 {
+		minScore = rule.ChallengeScore
+	}
+
+	opts := []VerifyOption{
+		WithMaxAge(time.Duration(rule.MaxAge)),
+		WithClockSkew(time.Duration(p.ClockSkew)),
+		WithClock(p.Clock),
+	}
+
+	if len(rule.APKPackageNames) != 0 {
+		opts = append(opts, WithAPKPackageNames(rule.APKPackageNames...))
+	}
+
+	if err := r.Verify(action, minScore, hostnames, opts...); err != nil {
+		if errors.Is(err, ErrFallback) {
+			d.Outcome = OutcomeChallenge
+		}
+
+		d.Reason = err
+
+		return d
+	}
+
+	if !r.degraded && r.Score < rule.MinScore {
+		d.Outcome = OutcomeChallenge
+		d.Reason = &errBelowMinScore{Score: r.Score, MinScore: rule.MinScore}
+
+		return d
+	}
+
+	d.Outcome = OutcomeAllow
+
+	return d
+}
+
+// Outcome is the result of evaluating a response against a Policy.
+type Outcome int
+
+const (
+	// OutcomeDeny means the request should be rejected.
+	OutcomeDeny Outcome = iota
+	// OutcomeAllow means the request may continue.
+	OutcomeAllow
+	// OutcomeChallenge means the request should only continue after the user passes a second
+	// challenge.
+	OutcomeChallenge
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeDeny:
+		return "deny"
+	case OutcomeAllow:
+		return "allow"
+	case OutcomeChallenge:
+		return "challenge"
+	default:
+		return "unknown"
+	}
+}
+
+// Decision is the result of Policy.Evaluate.
+type Decision struct {
+	Outcome Outcome
+	// Action is the expected action the response was evaluated for.
+	Action string
+	// Score is the response score.
+	Score float64
+	// Rule is the rule that was applied, including the score thresholds that produced the outcome.
+	Rule Rule
+	// Reason is the error explaining why the request was not allowed. It is nil if the outcome is
+	// OutcomeAllow. For OutcomeChallenge and score based denials IsBelowMinScore reports true and the
+	// error message includes the threshold that was not met. If a Chain provider was unavailable the
+	// outcome is OutcomeChallenge and Reason is a *FallbackError naming the provider to use.
+	Reason error
+	// Provider is the name of the provider that produced the response, from Response.Provider.
+	Provider string
+	// Site is the ID of the Site whose policy was applied, if any.
+	Site string
+	// AssessmentName is the reCAPTCHA Enterprise assessment name from Response.AssessmentName.
+	AssessmentName string
+}
+
+// Allowed reports whether the outcome is OutcomeAllow.
+func (d Decision) Allowed() bool {
+	return d.Outcome == OutcomeAllow
+}
+
+// Challenged reports whether the outcome is OutcomeChallenge.
+func (d Decision) Challenged() bool {
+	return d.Outcome == OutcomeChallenge
+}
+
+// Duration is a time.Duration that is encoded in JSON as a string such as "1m30s".
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"2m\": %w", err)
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+
+	return nil
+}
@@ -0,0 +1,351 @@
+package recaptchav3
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPolicyJSON = `{
+  "actions": {
+    "login": {"min_score": 0.7, "hostnames": ["example.com"], "max_age": "2m"},
+    "homepage": {"min_score": 0.3}
+  },
+  "default": {"min_score": 0.5}
+}`
+
+func TestParsePolicy(t *testing.T) {
+	// arrange
+	expected := &Policy{
+		Actions: map[string]Rule{
+			"login":    {MinScore: 0.7, Hostnames: []string{"example.com"}, MaxAge: Duration(2 * time.Minute)},
+			"homepage": {MinScore: 0.3},
+		},
+		Default: &Rule{MinScore: 0.5},
+	}
+
+	// act
+	actual, err := ParsePolicy([]byte(testPolicyJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("want:\n---\n%+v\n---\n\ngot:\n---\n%+v\n---\n", expected, actual)
+	}
+}
+
+func TestParsePolicy_Invalid(t *testing.T) {
+	cases := []struct {
+		testName string
+
+		json     string
+		expected string
+	}{
+		{
+			testName: "BadJSON",
+			json:     `{"actions": [}`,
+			expected: "recaptchav3: parse policy: invalid character",
+		},
+		{
+			testName: "MinScoreOutOfRange",
+			json:     `{"actions": {"login": {"min_score": 1.5}}}`,
+			expected: "recaptchav3: parse policy: action 'login': min_score '1.5' not in range 0.0 - 1.0",
+		},
+		{
+			testName: "NegativeMaxAge",
+			json:     `{"default": {"max_age": "-1m"}}`,
+			expected: "recaptchav3: parse policy: default: max_age '-1m0s' is negative",
+		},
+		{
+			testName: "NumericMaxAge",
+			json:     `{"default": {"max_age": 120}}`,
+			expected: "recaptchav3: parse policy: duration must be a string",
+		},
+		{
+			testName: "BareWildcardHostname",
+			json:     `{"actions": {"login": {"hostnames": ["*."]}}}`,
+			expected: "recaptchav3: parse policy: action 'login': hostname pattern '*.' is invalid",
+		},
+		{
+			testName: "DotHostname",
+			json:     `{"default": {"hostnames": ["."]}}`,
+			expected: "recaptchav3: parse policy: default: hostname pattern '.' is invalid",
+		},
+		{
+			testName: "EmptyHostname",
+			json:     `{"default": {"hostnames": [""]}}`,
+			expected: "recaptchav3: parse policy: default: hostname pattern '' is invalid",
+		},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			_, err := ParsePolicy([]byte(tc.json))
+
+			// assert
+			if err == nil {
+				t.Fatalf("want: '%v' got: <nil>", tc.expected)
+			}
+
+			if !strings.HasPrefix(err.Error(), tc.expected) {
+				t.Errorf("want: '%v' got: '%v'", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicyJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+
+	cases := []struct {
+		testName string
+
+		action   string
+		response Response
+		outcome  Outcome
+		reason   error
+	}{
+		{
+			testName: "Allow",
+			action:   "login",
+			response: Response{Success: true, Action: "login", Hostname: "example.com", Score: 0.9, ChallengeTS: now},
+			outcome:  OutcomeAllow,
+		},
+		{
+			testName: "BelowMinScore",
+			action:   "login",
+			response: Response{Success: true, Action: "login", Hostname: "example.com", Score: 0.5, ChallengeTS: now},
+			outcome:  OutcomeDeny,
+			reason:   &errBelowMinScore{},
+		},
+		{
+			testName: "HostnameMismatch",
+			action:   "login",
+			response: Response{Success: true, Action: "login", Hostname: "evil.com", Score: 0.9, ChallengeTS: now},
+			outcome:  OutcomeDeny,
+			reason:   ErrHostnameMismatch,
+		},
+		{
+			testName: "ActionMismatch",
+			action:   "login",
+			response: Response{Success: true, Action: "homepage", Hostname: "example.com", Score: 0.9, ChallengeTS: now},
+			outcome:  OutcomeDeny,
+			reason:   ErrActionMismatch,
+		},
+		{
+			testName: "LowerThresholdForAction",
+			action:   "homepage",
+			response: Response{Success: true, Action: "homepage", Score: 0.4},
+			outcome:  OutcomeAllow,
+		},
+		{
+			testName: "DefaultRule",
+			action:   "signup",
+			response: Response{Success: true, Action: "signup", Score: 0.4},
+			outcome:  OutcomeDeny,
+			reason:   &errBelowMinScore{},
+		},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			d := policy.Evaluate(tc.action, tc.response)
+
+			// assert
+			if tc.outcome != d.Outcome {
+				t.Errorf("Outcome, want: %v got: %v", tc.outcome, d.Outcome)
+			}
+
+			if tc.reason == nil && d.Reason != nil {
+				t.Errorf("Reason, want: <nil> got: '%v'", d.Reason)
+			} else if tc.reason != nil && !errors.Is(d.Reason, tc.reason) {
+				t.Errorf("Reason, want: '%v' got: '%v'", tc.reason, d.Reason)
+			}
+
+			if tc.action != d.Action {
+				t.Errorf("Action, want: '%v' got: '%v'", tc.action, d.Action)
+			}
+		})
+	}
+}
+
+func TestPolicy_Evaluate_MaxAge(t *testing.T) {
+	// arrange
+	now := time.Date(2020, 1, 24, 14, 47, 44, 0, time.UTC)
+
+	policy := &Policy{
+		Default:   &Rule{MaxAge: Duration(2 * time.Minute)},
+		ClockSkew: Duration(10 * time.Second),
+		Clock:     func() time.Time { return now },
+	}
+
+	cases := []struct {
+		testName string
+
+		challengeTS time.Time
+		expected    error
+	}{
+		{testName: "Fresh", challengeTS: now.Add(-time.Minute)},
+		{testName: "WithinSkew", challengeTS: now.Add(-2*time.Minute - 5*time.Second)},
+		{testName: "Expired", challengeTS: now.Add(-3 * time.Minute), expected: ErrChallengeExpired},
+		{testName: "Future", challengeTS: now.Add(time.Minute), expected: ErrChallengeInFuture},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			d := policy.Evaluate("", Response{Success: true, ChallengeTS: tc.challengeTS})
+
+			// assert
+			if tc.expected == nil {
+				if !d.Allowed() {
+					t.Errorf("want: allow got: %v '%v'", d.Outcome, d.Reason)
+				}
+
+				return
+			}
+
+			if d.Allowed() {
+				t.Error("want: deny got: allow")
+			}
+
+			if !errors.Is(d.Reason, tc.expected) {
+				t.Errorf("Reason, want: '%v' got: '%v'", tc.expected, d.Reason)
+			}
+		})
+	}
+}
+
+func TestPolicy_Evaluate_NoRule(t *testing.T) {
+	// arrange
+	policy := &Policy{Actions: map[string]Rule{"login": {}}}
+
+	// act
+	d := policy.Evaluate("signup", Response{Success: true, Action: "signup"})
+
+	// assert
+	if d.Allowed() {
+		t.Error("want: deny got: allow")
+	}
+
+	if d.Reason != ErrNoRule {
+		t.Errorf("Reason, want: '%v' got: '%v'", ErrNoRule, d.Reason)
+	}
+}
+
+func TestDuration_MarshalJSON(t *testing.T) {
+	// arrange
+	rule := Rule{MinScore: 0.5, MaxAge: Duration(90 * time.Second)}
+
+	const expected = `{"min_score":0.5,"max_age":"1m30s"}`
+
+	// act
+	b, err := json.Marshal(rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if expected != string(b) {
+		t.Errorf("want: '%s' got: '%s'", expected, b)
+	}
+}
+
+func TestPolicy_Evaluate_Bands(t *testing.T) {
+	policy := &Policy{
+		Actions: map[string]Rule{
+			"login": {MinScore: 0.7, ChallengeScore: 0.3},
+		},
+	}
+
+	cases := []struct {
+		testName string
+
+		score     float64
+		outcome   Outcome
+		threshold float64
+	}{
+		{testName: "Allow", score: 0.9, outcome: OutcomeAllow},
+		{testName: "AllowAtMinScore", score: 0.7, outcome: OutcomeAllow},
+		{testName: "Challenge", score: 0.5, outcome: OutcomeChallenge, threshold: 0.7},
+		{testName: "ChallengeAtChallengeScore", score: 0.3, outcome: OutcomeChallenge, threshold: 0.7},
+		{testName: "Deny", score: 0.1, outcome: OutcomeDeny, threshold: 0.3},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			d := policy.Evaluate("login", Response{Success: true, Action: "login", Score: tc.score})
+
+			// assert
+			if tc.outcome != d.Outcome {
+				t.Errorf("Outcome, want: %v got: %v", tc.outcome, d.Outcome)
+			}
+
+			if tc.outcome == OutcomeAllow {
+				if d.Reason != nil {
+					t.Errorf("Reason, want: <nil> got: '%v'", d.Reason)
+				}
+
+				return
+			}
+
+			var reason *errBelowMinScore
+			if !errors.As(d.Reason, &reason) {
+				t.Fatalf("Reason, want: %T got: %T", reason, d.Reason)
+			}
+
+			if tc.threshold != reason.MinScore {
+				t.Errorf("threshold, want: %v got: %v", tc.threshold, reason.MinScore)
+			}
+		})
+	}
+}
+
+func TestParsePolicy_ChallengeScoreAboveMinScore(t *testing.T) {
+	// arrange
+	const expected = "recaptchav3: parse policy: action 'login': challenge_score '0.8' not in range 0.0 - 0.5"
+
+	// act
+	_, err := ParsePolicy([]byte(`{"actions": {"login": {"min_score": 0.5, "challenge_score": 0.8}}}`))
+
+	// assert
+	if err == nil || expected != err.Error() {
+		t.Errorf("want: '%v' got: '%v'", expected, err)
+	}
+}
+
+func TestPolicy_Evaluate_APKPackageNames(t *testing.T) {
+	// arrange
+	policy := &Policy{
+		Default: &Rule{Hostnames: []string{"example.com"}, APKPackageNames: []string{"com.example.app"}},
+	}
+
+	web := Response{Success: true, Hostname: "example.com"}
+	android := Response{Success: true, APKPackageName: "com.example.app"}
+
+	// act/assert
+	if d := policy.Evaluate("", web); !d.Allowed() {
+		t.Errorf("web, want: allow got: %v '%v'", d.Outcome, d.Reason)
+	}
+
+	if d := policy.Evaluate("", android); !d.Allowed() {
+		t.Errorf("android, want: allow got: %v '%v'", d.Outcome, d.Reason)
+	}
+}
@@ -0,0 +1,163 @@
+package recaptchav3
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+const (
+	hCaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+	turnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+// Provider is a CAPTCHA service with a siteverify endpoint compatible with reCAPTCHA's. A Client
+// uses GoogleProvider unless another is set with WithProvider, so verification code written
+// against Response works with any provider.
+type Provider interface {
+	// Name returns a short name for the provider, such as "google". It is recorded in
+	// Response.Provider.
+	Name() string
+	// URL returns the siteverify endpoint URL. WithURL overrides it.
+	URL() string
+	// Form returns the form posted to the endpoint. It is called once per verification, so the
+	// same form is sent again if the request is retried.
+	Form(secretKey, captchaResponse, remoteIP string) url.Values
+	// Decode decodes the body of a 200 OK response.
+	Decode(body []byte) (Response, error)
+}
+
+// GoogleProvider is the reCAPTCHA v2 and v3 siteverify endpoint. It is the default Provider.
+type GoogleProvider struct{}
+
+// Name returns "google".
+func (GoogleProvider) Name() string { return "google" }
+
+// URL returns https://www.google.com/recaptcha/api/siteverify.
+func (GoogleProvider) URL() string { return siteVerifyURL }
+
+// Form returns the secret, response and remoteip parameters.
+func (GoogleProvider) Form(secretKey, captchaResponse, remoteIP string) url.Values {
+	return siteVerifyForm(secretKey, captchaResponse, remoteIP)
+}
+
+// Decode decodes body as a Response.
+func (GoogleProvider) Decode(body []byte) (Response, error) {
+	var r Response
+	err := json.Unmarshal(body, &r)
+
+	return r, err
+}
+
+// HCaptchaProvider is the hCaptcha siteverify endpoint.
+//
+// hCaptcha responses have no action, so verify them with an empty action. hCaptcha Enterprise
+// scores risk, with 1.0 being the most likely to be a bot; Decode inverts the score so that, as with
+// reCAPTCHA, higher is better. Responses without a score are given 1.0 if successful.
+type HCaptchaProvider struct {
+	// SiteKey is sent with each request, if set, so hCaptcha checks the token was issued for it.
+	SiteKey string
+}
+
+// Name returns "hcaptcha".
+func (HCaptchaProvider) Name() string { return "hcaptcha" }
+
+// URL returns https://api.hcaptcha.com/siteverify.
+func (HCaptchaProvider) URL() string { return hCaptchaVerifyURL }
+
+// Form returns the secret, response, remoteip and sitekey parameters.
+func (p HCaptchaProvider) Form(secretKey, captchaResponse, remoteIP string) url.Values {
+	data := siteVerifyForm(secretKey, captchaResponse, remoteIP)
+	if p.SiteKey != "" {
+		data.Set("sitekey", p.SiteKey)
+	}
+
+	return data
+}
+
+// Decode decodes body as a Response, converting the risk score.
+func (HCaptchaProvider) Decode(body []byte) (Response, error) {
+	var obj struct {
+		Response
+		Score *float64 `json:"score"`
+	}
+
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return Response{}, err
+	}
+
+	r := obj.Response
+
+	switch {
+	case obj.Score != nil:
+		r.Score = 1 - *obj.Score
+	case r.Success:
+		r.Score = 1
+	}
+
+	return r, nil
+}
+
+// TurnstileProvider is the Cloudflare Turnstile siteverify endpoint.
+//
+// Each verification is sent with a random idempotency_key, which is reused if the request is
+// retried so a retry of a request that reached Cloudflare is not rejected as a duplicate. Turnstile
+// responses have no score; successful responses are given a score of 1.0.
+type TurnstileProvider struct{}
+
+// Name returns "turnstile".
+func (TurnstileProvider) Name() string { return "turnstile" }
+
+// URL returns https://challenges.cloudflare.com/turnstile/v0/siteverify.
+func (TurnstileProvider) URL() string { return turnstileVerifyURL }
+
+// Form returns the secret, response, remoteip and idempotency_key parameters.
+func (TurnstileProvider) Form(secretKey, captchaResponse, remoteIP string) url.Values {
+	data := siteVerifyForm(secretKey, captchaResponse, remoteIP)
+	if key, err := newIdempotencyKey(); err == nil {
+		data.Set("idempotency_key", key)
+	}
+
+	return data
+}
+
+// Decode decodes body as a Response.
+func (TurnstileProvider) Decode(body []byte) (Response, error) {
+	var r Response
+	if err := json.Unmarshal(body, &r); err != nil {
+		return Response{}, err
+	}
+
+	if r.Success {
+		r.Score = 1
+	}
+
+	return r, nil
+}
+
+// siteVerifyForm returns the parameters common to all providers.
+func siteVerifyForm(secretKey, captchaResponse, remoteIP string) url.Values {
+	data := make(url.Values, 4)
+	data.Set("secret", secretKey)
+	data.Set("response", captchaResponse)
+
+	if remoteIP != "" {
+		data.Set("remoteip", remoteIP)
+	}
+
+	return data
+}
+
+// newIdempotencyKey returns a random version 4 UUID.
+func newIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
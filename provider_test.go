@@ -0,0 +1,151 @@
+package recaptchav3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestNewClient_Provider(t *testing.T) {
+	cases := []struct {
+		testName string
+
+		opts     []Option
+		expected string
+	}{
+		{testName: "Default", expected: siteVerifyURL},
+		{testName: "HCaptcha", opts: []Option{WithProvider(HCaptchaProvider{})}, expected: hCaptchaVerifyURL},
+		{testName: "Turnstile", opts: []Option{WithProvider(TurnstileProvider{})}, expected: turnstileVerifyURL},
+		{testName: "URLOverride", opts: []Option{WithURL("http://localhost"), WithProvider(TurnstileProvider{})}, expected: "http://localhost"},
+		{testName: "Nil", opts: []Option{WithProvider(nil)}, expected: siteVerifyURL},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			client := NewClient(tc.opts...)
+
+			// assert
+			if client.url != tc.expected {
+				t.Errorf("url, want: '%v' got: '%v'", tc.expected, client.url)
+			}
+		})
+	}
+}
+
+func TestProvider_Form(t *testing.T) {
+	// act
+	google := GoogleProvider{}.Form("secret", "token", "127.0.0.1")
+	hcaptcha := HCaptchaProvider{SiteKey: "site"}.Form("secret", "token", "")
+	turnstile := TurnstileProvider{}.Form("secret", "token", "")
+
+	// assert
+	if expected := "remoteip=127.0.0.1&response=token&secret=secret"; google.Encode() != expected {
+		t.Errorf("google, want: '%v' got: '%v'", expected, google.Encode())
+	}
+
+	if expected := "response=token&secret=secret&sitekey=site"; hcaptcha.Encode() != expected {
+		t.Errorf("hcaptcha, want: '%v' got: '%v'", expected, hcaptcha.Encode())
+	}
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if key := turnstile.Get("idempotency_key"); !uuid.MatchString(key) {
+		t.Errorf("idempotency_key, want: UUID got: '%v'", key)
+	}
+}
+
+func TestProvider_Decode(t *testing.T) {
+	cases := []struct {
+		testName string
+
+		provider Provider
+		body     string
+		expected Response
+	}{
+		{
+			testName: "Google",
+			provider: GoogleProvider{},
+			body:     `{"success":true,"score":0.7,"action":"login"}`,
+			expected: Response{Success: true, Score: 0.7, Action: "login"},
+		},
+		{
+			testName: "HCaptchaNoScore",
+			provider: HCaptchaProvider{},
+			body:     `{"success":true,"hostname":"example.com"}`,
+			expected: Response{Success: true, Score: 1, Hostname: "example.com"},
+		},
+		{
+			testName: "HCaptchaFailed",
+			provider: HCaptchaProvider{},
+			body:     `{"success":false,"error-codes":["invalid-input-response"]}`,
+			expected: Response{ErrorCodes: []string{"invalid-input-response"}},
+		},
+		{
+			testName: "HCaptchaEnterprise",
+			provider: HCaptchaProvider{},
+			body:     `{"success":true,"score":0.25,"score_reason":["safe"]}`,
+			expected: Response{Success: true, Score: 0.75, ScoreReason: []string{"safe"}},
+		},
+		{
+			testName: "Turnstile",
+			provider: TurnstileProvider{},
+			body:     `{"success":true,"action":"login","cdata":"session-1"}`,
+			expected: Response{Success: true, Score: 1, Action: "login", CData: "session-1"},
+		},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			actual, err := tc.provider.Decode([]byte(tc.body))
+
+			// assert
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("want: %+v got: %+v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestClient_SiteVerify_TurnstileRetryReusesIdempotencyKey(t *testing.T) {
+	// arrange
+	var keys []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.PostFormValue("idempotency_key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`{"success":true,"action":"login"}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL), WithProvider(TurnstileProvider{}), WithRetry(testRetryPolicy))
+
+	// act
+	response := c.SiteVerify(context.Background(), "abc", "")
+
+	// assert
+	if err := response.Verify("login", 0.5, nil); err != nil {
+		t.Errorf("want: <nil> got: '%v'", err)
+	}
+
+	if response.Provider != "turnstile" {
+		t.Errorf("Provider, want: 'turnstile' got: '%v'", response.Provider)
+	}
+
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("idempotency keys, want: 2 equal got: %v", keys)
+	}
+}
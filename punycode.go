@@ -0,0 +1,162 @@
+package recaptchav3
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Punycode parameters from RFC 3492 section 5.
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+	punyACEPrefix   = "xn--"
+)
+
+var errPunycodeOverflow = errors.New("recaptchav3: punycode overflow")
+
+// toASCII converts a hostname to its lowercase ASCII form, encoding labels containing non-ASCII
+// characters with punycode. It does not apply the full IDNA mapping tables; labels are only
+// lowercased.
+func toASCII(hostname string) (string, error) {
+	hostname = strings.ToLower(hostname)
+
+	if isASCII(hostname) {
+		return hostname, nil
+	}
+
+	labels := strings.Split(hostname, ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			continue
+		}
+
+		encoded, err := punycodeEncode(label)
+		if err != nil {
+			return "", err
+		}
+
+		labels[i] = punyACEPrefix + encoded
+	}
+
+	return strings.Join(labels, "."), nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// punycodeEncode encodes s as described in RFC 3492 section 6.3.
+func punycodeEncode(s string) (string, error) {
+	runes := []rune(s)
+	out := make([]byte, 0, len(s)+len(runes))
+
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			out = append(out, byte(r))
+		}
+	}
+
+	b := len(out)
+	h := b
+
+	if b > 0 {
+		out = append(out, '-')
+	}
+
+	n, delta, bias := int32(punyInitialN), int32(0), int32(punyInitialBias)
+
+	for h < len(runes) {
+		m := int32(math.MaxInt32)
+		for _, r := range runes {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+
+		if (m - n) > (math.MaxInt32-delta)/int32(h+1) {
+			return "", errPunycodeOverflow
+		}
+
+		delta += (m - n) * int32(h+1)
+		n = m
+
+		for _, r := range runes {
+			if r < n {
+				delta++
+				if delta < 0 {
+					return "", errPunycodeOverflow
+				}
+			}
+
+			if r != n {
+				continue
+			}
+
+			q := delta
+			for k := int32(punyBase); ; k += punyBase {
+				t := k - bias
+				if t < punyTMin {
+					t = punyTMin
+				} else if t > punyTMax {
+					t = punyTMax
+				}
+
+				if q < t {
+					break
+				}
+
+				out = append(out, punycodeDigit(t+(q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+
+			out = append(out, punycodeDigit(q))
+			bias = punycodeAdapt(delta, int32(h+1), h == b)
+			delta = 0
+			h++
+		}
+
+		delta++
+		n++
+	}
+
+	return string(out), nil
+}
+
+func punycodeDigit(d int32) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+
+	return byte('0' + d - 26)
+}
+
+func punycodeAdapt(delta, numPoints int32, first bool) int32 {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+
+	delta += delta / numPoints
+
+	k := int32(0)
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
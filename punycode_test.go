@@ -0,0 +1,30 @@
+package recaptchav3
+
+import "testing"
+
+func TestToASCII(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "example.com", expected: "example.com"},
+		{input: "WWW.Example.COM", expected: "www.example.com"},
+		{input: "münchen.de", expected: "xn--mnchen-3ya.de"},
+		{input: "MÜNCHEN.de", expected: "xn--mnchen-3ya.de"},
+		{input: "bücher.example.com", expected: "xn--bcher-kva.example.com"},
+		{input: "例え.テスト", expected: "xn--r8jz45g.xn--zckzah"},
+		{input: "xn--mnchen-3ya.de", expected: "xn--mnchen-3ya.de"},
+	}
+
+	for _, tc := range cases {
+		actual, err := toASCII(tc.input)
+		if err != nil {
+			t.Errorf("toASCII('%s'): %v", tc.input, err)
+			continue
+		}
+
+		if tc.expected != actual {
+			t.Errorf("toASCII('%s'), want: '%s' got: '%s'", tc.input, tc.expected, actual)
+		}
+	}
+}
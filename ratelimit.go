@@ -0,0 +1,228 @@
+package recaptchav3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited matches any *RateLimitError with errors.Is.
+var ErrRateLimited = errors.New("recaptchav3: rate limited")
+
+// RateLimitError is returned by Response.Verify when a request to the siteverify endpoint was not
+// made because it would exceed the limit set by WithRateLimit.
+type RateLimitError struct {
+	// RemoteIP is the remote IP address whose limit was exceeded, or empty if the global limit was
+	// exceeded.
+	RemoteIP string
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RemoteIP == "" {
+		return "recaptchav3: rate limited"
+	}
+
+	return fmt.Sprintf("recaptchav3: rate limited for remote ip '%s'", e.RemoteIP)
+}
+
+// Is reports whether target is ErrRateLimited.
+func (*RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RateLimit limits the rate of requests to the siteverify endpoint with token buckets, so the
+// number of requests made while under attack is bounded. Tokens rejected before a request is made,
+// such as replayed or malformed tokens, do not count towards the limit.
+type RateLimit struct {
+	// Rate is the sustained number of requests per second for all remote IPs. Zero means no global
+	// limit.
+	Rate float64
+	// Burst is the number of requests that may be made at once before Rate applies. The minimum is 1.
+	Burst int
+	// PerIPRate is the sustained number of requests per second for each remote IP. Zero means no
+	// per-IP limit. Requests without a remote IP are only subject to the global limit.
+	PerIPRate float64
+	// PerIPBurst is like Burst for PerIPRate.
+	PerIPBurst int
+	// Wait makes requests over the limit wait until they are allowed, unless that would pass the
+	// context deadline, instead of failing immediately with a *RateLimitError.
+	Wait bool
+}
+
+// WithRateLimit limits the rate of requests to the siteverify endpoint. By default there is no
+// limit.
+func WithRateLimit(limit RateLimit) Option {
+	return func(c *Client) {
+		c.limiter = newRateLimiter(limit)
+	}
+}
+
+type rateLimiter struct {
+	limit RateLimit
+	now   func() time.Time
+
+	mu        sync.Mutex
+	global    bucket
+	ips       map[string]*bucket
+	nextSweep time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+
+	if limit.PerIPBurst < 1 {
+		limit.PerIPBurst = 1
+	}
+
+	return &rateLimiter{
+		limit:  limit,
+		now:    time.Now,
+		global: bucket{tokens: float64(limit.Burst)},
+		ips:    make(map[string]*bucket),
+	}
+}
+
+// wait takes a token for a request from remoteIP, waiting for one if the limit allows it. It
+// returns a *RateLimitError if no token is available, or a *TransportError if ctx is done while
+// waiting. It never waits with a nil ctx, leaving the request to fail as it would without a limit.
+func (l *rateLimiter) wait(ctx context.Context, remoteIP string) error {
+	now := l.now()
+
+	var maxWait time.Duration
+	if l.limit.Wait && ctx != nil {
+		maxWait = math.MaxInt64
+		if deadline, ok := ctx.Deadline(); ok {
+			maxWait = deadline.Sub(now)
+		}
+	}
+
+	d, err := l.reserve(now, remoteIP, maxWait)
+	if err != nil || d == 0 {
+		return err
+	}
+
+	if !sleep(ctx, d) {
+		l.cancel(remoteIP)
+
+		if ctx.Err() != nil {
+			return &TransportError{Err: ctx.Err()}
+		}
+
+		return &RateLimitError{}
+	}
+
+	return nil
+}
+
+// reserve takes a token from the global bucket and the bucket for remoteIP, and returns how long to
+// wait before using them. It takes no tokens if that would be longer than maxWait.
+func (l *rateLimiter) reserve(now time.Time, remoteIP string, maxWait time.Duration) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var d time.Duration
+
+	if l.limit.Rate > 0 {
+		var ok bool
+		if d, ok = l.global.reserve(now, l.limit.Rate, l.limit.Burst, maxWait); !ok {
+			return 0, &RateLimitError{}
+		}
+	}
+
+	if l.limit.PerIPRate <= 0 || remoteIP == "" {
+		return d, nil
+	}
+
+	l.sweep(now)
+
+	b, ok := l.ips[remoteIP]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.PerIPBurst), last: now}
+		l.ips[remoteIP] = b
+	}
+
+	ipWait, ok := b.reserve(now, l.limit.PerIPRate, l.limit.PerIPBurst, maxWait)
+	if !ok {
+		if l.limit.Rate > 0 {
+			l.global.tokens++
+		}
+
+		return 0, &RateLimitError{RemoteIP: remoteIP}
+	}
+
+	if ipWait > d {
+		d = ipWait
+	}
+
+	return d, nil
+}
+
+// cancel returns the tokens taken by reserve.
+func (l *rateLimiter) cancel(remoteIP string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit.Rate > 0 {
+		l.global.tokens++
+	}
+
+	if b, ok := l.ips[remoteIP]; ok {
+		b.tokens++
+	}
+}
+
+// sweep removes the buckets of remote IPs that have refilled, as they are equivalent to new
+// buckets. It runs at most once per time taken to refill a bucket.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+
+	for ip, b := range l.ips {
+		if b.refill(now, l.limit.PerIPRate, l.limit.PerIPBurst) >= float64(l.limit.PerIPBurst) {
+			delete(l.ips, ip)
+		}
+	}
+
+	l.nextSweep = now.Add(time.Duration(float64(l.limit.PerIPBurst) / l.limit.PerIPRate * float64(time.Second)))
+}
+
+// bucket is a token bucket. The number of tokens is negative while requests are waiting for them.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accumulated since the last update and returns the number of tokens.
+func (b *bucket) refill(now time.Time, rate float64, burst int) float64 {
+	if now.After(b.last) {
+		b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+		b.last = now
+	}
+
+	return b.tokens
+}
+
+// reserve takes a token and returns how long to wait until it is available. It reports false, and
+// takes no token, if that would be longer than maxWait.
+func (b *bucket) reserve(now time.Time, rate float64, burst int, maxWait time.Duration) (time.Duration, bool) {
+	tokens := b.refill(now, rate, burst) - 1
+	if tokens >= 0 {
+		b.tokens = tokens
+		return 0, true
+	}
+
+	wait := time.Duration(-tokens / rate * float64(time.Second))
+	if wait > maxWait {
+		return 0, false
+	}
+
+	b.tokens = tokens
+
+	return wait, true
+}
@@ -0,0 +1,233 @@
+package recaptchav3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRateLimiter(limit RateLimit) (*rateLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2020, 1, 24, 14, 47, 44, 0, time.UTC)}
+
+	l := newRateLimiter(limit)
+	l.now = clock.now
+
+	return l, clock
+}
+
+func TestRateLimiter_Global(t *testing.T) {
+	// arrange
+	l, clock := newTestRateLimiter(RateLimit{Rate: 2, Burst: 2})
+	ctx := context.Background()
+
+	// act
+	first := l.wait(ctx, "")
+	second := l.wait(ctx, "")
+	third := l.wait(ctx, "")
+
+	clock.advance(500 * time.Millisecond)
+	refilled := l.wait(ctx, "")
+
+	// assert
+	if first != nil || second != nil {
+		t.Errorf("burst, want: <nil> <nil> got: '%v' '%v'", first, second)
+	}
+
+	var target *RateLimitError
+	if !errors.As(third, &target) || target.RemoteIP != "" {
+		t.Errorf("want: global %T got: '%v'", target, third)
+	}
+
+	if refilled != nil {
+		t.Errorf("refilled, want: <nil> got: '%v'", refilled)
+	}
+}
+
+func TestRateLimiter_PerIP(t *testing.T) {
+	// arrange
+	l, _ := newTestRateLimiter(RateLimit{PerIPRate: 1, PerIPBurst: 1})
+	ctx := context.Background()
+
+	// act
+	first := l.wait(ctx, "203.0.113.7")
+	limited := l.wait(ctx, "203.0.113.7")
+	other := l.wait(ctx, "203.0.113.8")
+	noIP := l.wait(ctx, "")
+
+	// assert
+	if first != nil || other != nil || noIP != nil {
+		t.Errorf("want: <nil> got: '%v' '%v' '%v'", first, other, noIP)
+	}
+
+	var target *RateLimitError
+	if !errors.As(limited, &target) || target.RemoteIP != "203.0.113.7" {
+		t.Errorf("want: %T for 203.0.113.7 got: '%v'", target, limited)
+	}
+}
+
+func TestRateLimiter_PerIPLimitReturnsGlobalToken(t *testing.T) {
+	// arrange
+	l, _ := newTestRateLimiter(RateLimit{Rate: 1, Burst: 2, PerIPRate: 1, PerIPBurst: 1})
+	ctx := context.Background()
+
+	// act
+	l.wait(ctx, "203.0.113.7")
+	limited := l.wait(ctx, "203.0.113.7")
+	other := l.wait(ctx, "203.0.113.8")
+
+	// assert
+	if !errors.Is(limited, ErrRateLimited) {
+		t.Errorf("want: '%v' got: '%v'", ErrRateLimited, limited)
+	}
+
+	if other != nil {
+		t.Errorf("other, want: <nil> got: '%v'", other)
+	}
+}
+
+func TestRateLimiter_Sweep(t *testing.T) {
+	// arrange
+	l, clock := newTestRateLimiter(RateLimit{PerIPRate: 1, PerIPBurst: 1})
+
+	l.wait(context.Background(), "203.0.113.7")
+	clock.advance(2 * time.Second)
+
+	// act
+	l.wait(context.Background(), "203.0.113.8")
+
+	// assert
+	if _, ok := l.ips["203.0.113.7"]; ok || len(l.ips) != 1 {
+		t.Errorf("ips, want: [203.0.113.8] got: %v", l.ips)
+	}
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	// arrange
+	l := newRateLimiter(RateLimit{Rate: 20, Burst: 1, Wait: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	l.wait(ctx, "")
+
+	// act
+	start := time.Now()
+	err := l.wait(ctx, "")
+	elapsed := time.Since(start)
+
+	// assert
+	if err != nil {
+		t.Errorf("want: <nil> got: '%v'", err)
+	}
+
+	if elapsed < 25*time.Millisecond {
+		t.Errorf("elapsed, want: ~50ms got: %v", elapsed)
+	}
+}
+
+func TestRateLimiter_WaitPastDeadline(t *testing.T) {
+	// arrange
+	l := newRateLimiter(RateLimit{Rate: 1, Burst: 1, Wait: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	l.wait(ctx, "")
+
+	// act
+	start := time.Now()
+	err := l.wait(ctx, "")
+
+	// assert
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("want: '%v' got: '%v'", ErrRateLimited, err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("elapsed, want: no wait got: %v", elapsed)
+	}
+}
+
+func TestRateLimiter_WaitNilContext(t *testing.T) {
+	// arrange
+	l, _ := newTestRateLimiter(RateLimit{Rate: 1, Burst: 1, Wait: true})
+
+	var ctx context.Context
+
+	// act
+	first := l.wait(ctx, "")
+	limited := l.wait(ctx, "")
+
+	// assert
+	if first != nil {
+		t.Errorf("first, want: <nil> got: '%v'", first)
+	}
+
+	if !errors.Is(limited, ErrRateLimited) {
+		t.Errorf("want: '%v' got: '%v'", ErrRateLimited, limited)
+	}
+}
+
+func TestClient_SiteVerify_RateLimitedNilContext(t *testing.T) {
+	// arrange
+	ts := newTestServer(time.Now().UTC(), nil)
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL), WithRateLimit(RateLimit{Rate: 1, Burst: 1, Wait: true}))
+
+	var ctx context.Context
+
+	// act
+	err := c.SiteVerify(ctx, "def", "").Verify(defaultAction, defaultMinScore, nil)
+
+	// assert
+	var target *TransportError
+	if !errors.As(err, &target) {
+		t.Errorf("want: %T got: '%v'", target, err)
+	}
+}
+
+func TestClient_SiteVerify_RateLimited(t *testing.T) {
+	// arrange
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"success":true,"action":"homepage","score":0.9}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(
+		WithURL(ts.URL),
+		WithRateLimit(RateLimit{Rate: 0.001, Burst: 1}),
+		WithTokenStore(NewMemoryTokenStore(), time.Minute),
+	)
+
+	// act
+	first := c.SiteVerify(context.Background(), "first", "").Verify(defaultAction, defaultMinScore, nil)
+	limited := c.SiteVerify(context.Background(), "second", "").Verify(defaultAction, defaultMinScore, nil)
+
+	c.limiter.global.tokens = 1
+	retried := c.SiteVerify(context.Background(), "second", "").Verify(defaultAction, defaultMinScore, nil)
+
+	// assert
+	if first != nil {
+		t.Errorf("first, want: <nil> got: '%v'", first)
+	}
+
+	if !errors.Is(limited, ErrRateLimited) {
+		t.Errorf("limited, want: '%v' got: '%v'", ErrRateLimited, limited)
+	}
+
+	if retried != nil {
+		t.Errorf("retried, want: <nil> got: '%v'", retried)
+	}
+
+	if requests != 2 {
+		t.Errorf("requests, want: 2 got: %d", requests)
+	}
+}
@@ -0,0 +1,150 @@
+package recaptchav3
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ParseTrustedProxies parses a list of CIDR ranges, such as "10.0.0.0/8", or single IP addresses
+// for use with RemoteIP.
+func ParseTrustedProxies(cidrs ...string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("recaptchav3: invalid trusted proxy '%s'", cidr)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("recaptchav3: invalid trusted proxy '%s': %w", cidr, err)
+		}
+
+		nets = append(nets, n)
+	}
+
+	return nets, nil
+}
+
+// RemoteIP returns the IP address of the client that made r, for use as the remoteIP parameter of
+// SiteVerify.
+//
+// If the peer address, r.RemoteAddr, is in trustedProxies the forwarding headers are consulted:
+// the Forwarded header (RFC 7239) if present, otherwise X-Forwarded-For. Their entries are walked
+// from the right, skipping trusted proxies, and the first untrusted address is returned. Entries to
+// the left of it could have been set by the client and are never used. If an entry that must be
+// used is not an IP address, such as an obfuscated identifier, RemoteIP returns an empty string.
+//
+// With no trusted proxies RemoteIP returns the peer address and ignores the headers. It returns an
+// empty string if the peer address is not an IP address.
+func RemoteIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	ip := remoteAddrIP(r)
+	if !isTrusted(ip, trustedProxies) {
+		return ip
+	}
+
+	var hops []string
+	if values := r.Header["Forwarded"]; len(values) != 0 {
+		hops = forwardedFor(values)
+	} else {
+		for _, value := range r.Header["X-Forwarded-For"] {
+			hops = append(hops, strings.Split(value, ",")...)
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseHop(hops[i])
+		if hop == "" {
+			return ""
+		}
+
+		ip = hop
+		if !isTrusted(ip, trustedProxies) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// remoteAddrIP returns the IP address of the peer that sent r, or an empty string if r.RemoteAddr
+// is not an IP address, such as the address of a Unix socket peer.
+func remoteAddrIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+
+	return ip.String()
+}
+
+func isTrusted(ip string, trustedProxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, n := range trustedProxies {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// forwardedFor returns the for parameters of the Forwarded header values in order.
+func forwardedFor(values []string) []string {
+	var hops []string
+
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+				if len(kv) == 2 && strings.EqualFold(kv[0], "for") {
+					hops = append(hops, kv[1])
+				}
+			}
+		}
+	}
+
+	return hops
+}
+
+// parseHop returns the IP address in a forwarding header entry, removing quotes, brackets and
+// ports. It returns an empty string if the entry is not an IP address.
+func parseHop(hop string) string {
+	hop = strings.Trim(strings.TrimSpace(hop), `"`)
+
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		hop = host
+	}
+
+	hop = strings.TrimSuffix(strings.TrimPrefix(hop, "["), "]")
+
+	ip := net.ParseIP(hop)
+	if ip == nil {
+		return ""
+	}
+
+	return ip.String()
+}
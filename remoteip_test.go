@@ -0,0 +1,192 @@
+package recaptchav3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	// act
+	nets, err := ParseTrustedProxies("10.0.0.0/8", "192.0.2.1", "2001:db8::/32", "::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	expected := []string{"10.0.0.0/8", "192.0.2.1/32", "2001:db8::/32", "::1/128"}
+
+	actual := make([]string, len(nets))
+	for i, n := range nets {
+		actual[i] = n.String()
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("want: '%v' got: '%v'", expected, actual)
+	}
+}
+
+func TestParseTrustedProxies_Invalid(t *testing.T) {
+	for _, cidr := range []string{"bogus", "10.0.0.0/33"} {
+		if _, err := ParseTrustedProxies(cidr); err == nil {
+			t.Errorf("ParseTrustedProxies('%s'), want: error got: <nil>", cidr)
+		}
+	}
+}
+
+func TestRemoteIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8", "2001:db8::/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		testName string
+
+		remoteAddr string
+		headers    map[string][]string
+		trusted    bool
+		expected   string
+	}{
+		{
+			testName:   "NoTrustedProxies",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.7"}},
+			expected:   "10.0.0.1",
+		},
+		{
+			testName:   "UntrustedPeerIgnoresHeaders",
+			remoteAddr: "198.51.100.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.7"}},
+			trusted:    true,
+			expected:   "198.51.100.1",
+		},
+		{
+			testName:   "XForwardedFor",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.7"}},
+			trusted:    true,
+			expected:   "203.0.113.7",
+		},
+		{
+			testName:   "XForwardedForSpoofedLeftmost",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.2.3.4, 203.0.113.7, 10.0.0.2"}},
+			trusted:    true,
+			expected:   "203.0.113.7",
+		},
+		{
+			testName:   "XForwardedForMultipleHeaders",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.2.3.4", "203.0.113.7, 10.0.0.2"}},
+			trusted:    true,
+			expected:   "203.0.113.7",
+		},
+		{
+			testName:   "XForwardedForAllTrusted",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			trusted:    true,
+			expected:   "10.0.0.3",
+		},
+		{
+			testName:   "XForwardedForGarbage",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.2.3.4, bogus"}},
+			trusted:    true,
+			expected:   "",
+		},
+		{
+			testName:   "Forwarded",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"Forwarded":       {`for=1.2.3.4, for="[2001:db8:cafe::17]:4711";proto=https, For=203.0.113.7;by=10.0.0.1`},
+				"X-Forwarded-For": {"198.51.100.9"},
+			},
+			trusted:  true,
+			expected: "203.0.113.7",
+		},
+		{
+			testName:   "ForwardedIPv6",
+			remoteAddr: "[2001:db8::1]:1234",
+			headers:    map[string][]string{"Forwarded": {`for="[2001:db9:cafe::17]:4711"`}},
+			trusted:    true,
+			expected:   "2001:db9:cafe::17",
+		},
+		{
+			testName:   "ForwardedObfuscated",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"Forwarded": {`for="_gazonk"`}},
+			trusted:    true,
+			expected:   "",
+		},
+		{
+			testName:   "UnixSocketPeer",
+			remoteAddr: "@",
+			expected:   "",
+		},
+		{
+			testName:   "NoHeaders",
+			remoteAddr: "10.0.0.1:1234",
+			trusted:    true,
+			expected:   "10.0.0.1",
+		},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// arrange
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.RemoteAddr = tc.remoteAddr
+
+			for k, v := range tc.headers {
+				r.Header[k] = v
+			}
+
+			proxies := trusted
+			if !tc.trusted {
+				proxies = nil
+			}
+
+			// act
+			actual := RemoteIP(r, proxies)
+
+			// assert
+			if tc.expected != actual {
+				t.Errorf("want: '%v' got: '%v'", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestClient_SiteVerifyRequest(t *testing.T) {
+	// arrange
+	echo := make(chan string, 1)
+
+	ts := newPOSTEchoServer(echo)
+	defer ts.Close()
+
+	trusted, err := ParseTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient(WithURL(ts.URL), WithSecretKey("abc"), WithTrustedProxies(trusted))
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil).WithContext(context.Background())
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+	const expected = "remoteip=203.0.113.7&response=def&secret=abc"
+
+	// act
+	c.SiteVerifyRequest(r, "def")
+
+	// assert
+	if actual := <-echo; expected != actual {
+		t.Errorf("want: '%v' got: '%v'", expected, actual)
+	}
+}
@@ -1,11 +1,6 @@
 package recaptchav3
 
-import (
-	"errors"
-	"fmt"
-	"strings"
-	"time"
-)
+import "time"
 
 // Response represents the reCAPTCHA response from SiteVerify.
 type Response struct {
@@ -21,68 +16,200 @@ type Response struct {
 	ChallengeTS time.Time `json:"challenge_ts"`
 	// Hostname of the site where the reCAPTCHA was solved.
 	Hostname string `json:"hostname"`
-	// ErrorCodes contains any errors with the request.
+	// APKPackageName is the package name of the Android app where the reCAPTCHA was solved. It is
+	// set instead of Hostname for tokens from the Android SDK.
+	APKPackageName string `json:"apk_package_name,omitempty"`
+	// ErrorCodes contains any errors with the request. See the ErrorCode constants for the known
+	// codes and the Codes method to get them as ErrorCode values.
 	//
 	// Reference: https://developers.google.com/recaptcha/docs/verify/#error_code_reference
-	//
-	//  Error code                  Description
-	//  missing-input-secret        The secret parameter is missing.
-	//  invalid-input-secret        The secret parameter is invalid or malformed.
-	//  missing-input-response      The response parameter is missing.
-	//  invalid-input-response      The response parameter is invalid or malformed.
-	//  bad-request                 The request is invalid or malformed.
-	//  timeout-or-duplicate        The response is no longer valid: either is too old or has been used previously.
 	ErrorCodes []string `json:"error-codes"`
+	// CData is the customer data set on the widget, returned by Turnstile.
+	CData string `json:"cdata,omitempty"`
+	// ScoreReason contains the reasons for the score, returned by hCaptcha Enterprise.
+	ScoreReason []string `json:"score_reason,omitempty"`
+	// Provider is the name of the Provider that verified the response, such as "google". It and the
+	// fields below are set locally, never decoded from the siteverify response.
+	Provider string `json:"-"`
+	// Endpoint is the siteverify endpoint URL the request was sent to, which may differ from the
+	// configured URL after WithEndpointFailover fails over.
+	Endpoint string `json:"-"`
+	// AssessmentName is the reCAPTCHA Enterprise assessment resource name for responses created by
+	// EnterpriseClient.Assess. Use it with EnterpriseClient.AnnotateAssessment.
+	AssessmentName string `json:"-"`
+
+	err      error
+	degraded bool
+}
 
-	err error
+// Degraded reports whether the response was not obtained from the siteverify endpoint because the
+// circuit breaker is open and its policy is FailOpen. Verify returns nil for degraded responses.
+func (r Response) Degraded() bool {
+	return r.degraded
 }
 
 // Verify verifies a response. The hostnames parameter is optional if "Verify the origin of reCAPTCHA
-// solutions" is checked in https://www.google.com/recaptcha/admin under "Settings".
-func (r Response) Verify(action string, minScore float64, hostnames []string) error {
+// solutions" is checked in https://www.google.com/recaptcha/admin under "Settings". Hostnames may
+// use the patterns described in HostnameMatcher.
+//
+// Errors from the request are returned as *TransportError, *HTTPStatusError or *DecodeError. Failed
+// checks are returned as *ErrorCodesError, ErrNotSuccess, *HostnameMismatchError,
+// *ActionMismatchError or an error for which IsBelowMinScore reports true. Additional checks may be
+// enabled with opts, such as WithMaxAge and WithAPKPackageNames.
+func (r Response) Verify(action string, minScore float64, hostnames []string, opts ...VerifyOption) error {
+	if err := r.verify(hostnames, opts); err != nil || r.degraded {
+		return err
+	}
+
+	if r.Action != action {
+		return &ActionMismatchError{Action: r.Action, Expected: action}
+	}
+
+	if r.Score < minScore {
+		return &errBelowMinScore{Score: r.Score, MinScore: minScore}
+	}
+
+	return nil
+}
+
+// VerifyV2 verifies a reCAPTCHA v2 checkbox or invisible response, such as one returned by
+// Client.SiteVerifyV2. It performs the same checks as Verify except for the action and score, which
+// v2 responses do not have.
+func (r Response) VerifyV2(hostnames []string, opts ...VerifyOption) error {
+	return r.verify(hostnames, opts)
+}
+
+// verify performs the checks common to Verify and VerifyV2.
+func (r Response) verify(hostnames []string, opts []VerifyOption) error {
+	cfg := verifyConfig{now: time.Now}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	if r.err != nil {
 		return r.err
 	}
 
+	if r.degraded {
+		return nil
+	}
+
 	if len(r.ErrorCodes) != 0 {
-		return fmt.Errorf("recaptchav3: %s", strings.Join(r.ErrorCodes, ","))
+		return &ErrorCodesError{Codes: r.Codes()}
 	}
 
 	if !r.Success {
-		return errors.New("recaptchav3: success = false")
+		return ErrNotSuccess
 	}
 
-	if err := checkHostnames(hostnames, r.Hostname); err != nil {
+	if err := cfg.checkChallengeTS(r.ChallengeTS); err != nil {
 		return err
 	}
 
-	if r.Action != action {
-		return fmt.Errorf("recaptchav3: action '%s' does not equal expected '%s'", r.Action, action)
+	if r.APKPackageName != "" && cfg.apkPackageNames != nil {
+		return cfg.checkAPKPackageName(r.APKPackageName)
 	}
 
-	if r.Score < minScore {
-		return &errBelowMinScore{Score: r.Score, MinScore: minScore}
+	return cfg.checkHostname(hostnames, r.Hostname)
+}
+
+func (cfg verifyConfig) checkHostname(hostnames []string, hostname string) error {
+	m := HostnameMatcher{Patterns: hostnames}
+	if cfg.hostnames != nil {
+		m = *cfg.hostnames
+	}
+
+	if len(m.Patterns) == 0 {
+		return nil
+	}
+
+	if !m.Match(hostname) {
+		return &HostnameMismatchError{Hostname: hostname, Expected: m.Patterns}
 	}
 
 	return nil
 }
 
-func checkHostnames(hostnames []string, hostname string) error {
-	if len(hostnames) == 0 {
-		return nil
+func (cfg verifyConfig) checkAPKPackageName(apkPackageName string) error {
+	for _, name := range cfg.apkPackageNames {
+		if apkPackageName == name {
+			return nil
+		}
 	}
 
-	found := false
+	return &APKPackageNameMismatchError{APKPackageName: apkPackageName, Expected: cfg.apkPackageNames}
+}
+
+// VerifyOption enables additional checks in Response.Verify and Response.VerifyV2.
+type VerifyOption func(*verifyConfig)
 
-	for _, hn := range hostnames {
-		if hostname == hn {
-			found = true
-			break
+type verifyConfig struct {
+	maxAge    time.Duration
+	clockSkew time.Duration
+	now       func() time.Time
+	hostnames *HostnameMatcher
+
+	apkPackageNames []string
+}
+
+// WithAPKPackageNames accepts responses from the Android apps with the given package names. A
+// response with an APKPackageName is checked against names instead of the hostnames, so the same
+// call can verify tokens from both web and Android clients.
+func WithAPKPackageNames(names ...string) VerifyOption {
+	return func(cfg *verifyConfig) {
+		cfg.apkPackageNames = append([]string{}, names...)
+	}
+}
+
+// WithHostnameMatcher checks the response hostname with m instead of the hostnames passed to
+// Verify, which should be nil.
+func WithHostnameMatcher(m HostnameMatcher) VerifyOption {
+	return func(cfg *verifyConfig) {
+		cfg.hostnames = &m
+	}
+}
+
+// WithMaxAge rejects responses whose challenge was loaded more than maxAge ago, or whose challenge
+// timestamp is in the future, with a *ChallengeTimeError. Zero disables the check.
+func WithMaxAge(maxAge time.Duration) VerifyOption {
+	return func(cfg *verifyConfig) {
+		cfg.maxAge = maxAge
+	}
+}
+
+// WithClockSkew sets how far the challenge timestamp may differ from the local clock, in either
+// direction, before WithMaxAge rejects it. The default is zero.
+func WithClockSkew(skew time.Duration) VerifyOption {
+	return func(cfg *verifyConfig) {
+		cfg.clockSkew = skew
+	}
+}
+
+// WithClock sets the function used to get the current time for WithMaxAge. The default is
+// time.Now.
+func WithClock(now func() time.Time) VerifyOption {
+	return func(cfg *verifyConfig) {
+		if now != nil {
+			cfg.now = now
 		}
 	}
+}
 
-	if !found {
-		return fmt.Errorf("recaptchav3: hostname '%s' not in '%s'", hostname, strings.Join(hostnames, ","))
+func (cfg verifyConfig) checkChallengeTS(challengeTS time.Time) error {
+	if cfg.maxAge <= 0 {
+		return nil
+	}
+
+	now := cfg.now()
+
+	if challengeTS.After(now.Add(cfg.clockSkew)) ||
+		now.Sub(challengeTS) > cfg.maxAge+cfg.clockSkew {
+		return &ChallengeTimeError{
+			ChallengeTS: challengeTS,
+			Now:         now,
+			MaxAge:      cfg.maxAge,
+			ClockSkew:   cfg.clockSkew,
+		}
 	}
 
 	return nil
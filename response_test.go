@@ -277,3 +277,292 @@ func TestResponseDeserialize(t *testing.T) {
 		t.Errorf("want:\n---\n%v\n---\n\ngot:\n---\n%v\n---\n", expected, actual)
 	}
 }
+
+func TestResponseDeserialize_LocalFields(t *testing.T) {
+	// arrange
+	const responseJSON = `{
+  "success": true,
+  "provider": "evil",
+  "endpoint": "https://evil.example.com",
+  "assessment_name": "projects/evil/assessments/1"
+}`
+
+	// act
+	var actual Response
+	if err := json.Unmarshal([]byte(responseJSON), &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if actual.Provider != "" || actual.Endpoint != "" || actual.AssessmentName != "" {
+		t.Errorf("want: empty got: '%v' '%v' '%v'", actual.Provider, actual.Endpoint, actual.AssessmentName)
+	}
+}
+
+func TestResponse_Verify_ErrorTypes(t *testing.T) {
+	hostnames := []string{"example.com", "www.example.com"}
+
+	t.Run("ErrorCodes", func(t *testing.T) {
+		resp := Response{ErrorCodes: []string{"timeout-or-duplicate"}}
+
+		var target *ErrorCodesError
+		if err := resp.Verify(defaultAction, defaultMinScore, nil); !errors.As(err, &target) {
+			t.Fatalf("want: %T got: %T", target, err)
+		}
+
+		if !reflect.DeepEqual([]ErrorCode{ErrorCodeTimeoutOrDuplicate}, target.Codes) {
+			t.Errorf("Codes, want: '[timeout-or-duplicate]' got: '%v'", target.Codes)
+		}
+	})
+
+	t.Run("NotSuccess", func(t *testing.T) {
+		resp := Response{}
+
+		if err := resp.Verify(defaultAction, defaultMinScore, nil); !errors.Is(err, ErrNotSuccess) {
+			t.Errorf("want: '%v' got: '%v'", ErrNotSuccess, err)
+		}
+	})
+
+	t.Run("HostnameMismatch", func(t *testing.T) {
+		resp := Response{Success: true, Action: defaultAction, Hostname: "fake.example.com", Score: 1.0}
+
+		err := resp.Verify(defaultAction, defaultMinScore, hostnames)
+		if !errors.Is(err, ErrHostnameMismatch) {
+			t.Errorf("want: '%v' got: '%v'", ErrHostnameMismatch, err)
+		}
+
+		var target *HostnameMismatchError
+		if !errors.As(err, &target) {
+			t.Fatalf("want: %T got: %T", target, err)
+		}
+
+		if target.Hostname != "fake.example.com" || !reflect.DeepEqual(hostnames, target.Expected) {
+			t.Errorf("want: 'fake.example.com' '%v' got: '%v' '%v'", hostnames, target.Hostname, target.Expected)
+		}
+	})
+
+	t.Run("ActionMismatch", func(t *testing.T) {
+		resp := Response{Success: true, Action: "login", Hostname: "example.com", Score: 1.0}
+
+		err := resp.Verify(defaultAction, defaultMinScore, hostnames)
+		if !errors.Is(err, ErrActionMismatch) {
+			t.Errorf("want: '%v' got: '%v'", ErrActionMismatch, err)
+		}
+
+		var target *ActionMismatchError
+		if !errors.As(err, &target) {
+			t.Fatalf("want: %T got: %T", target, err)
+		}
+
+		if target.Action != "login" || target.Expected != defaultAction {
+			t.Errorf("want: 'login' '%s' got: '%s' '%s'", defaultAction, target.Action, target.Expected)
+		}
+
+		if errors.Is(err, ErrHostnameMismatch) {
+			t.Errorf("want: not %v", ErrHostnameMismatch)
+		}
+	})
+}
+
+func TestResponse_Verify_MaxAge(t *testing.T) {
+	now := time.Date(2020, 1, 24, 14, 47, 44, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	cases := []struct {
+		testName string
+
+		challengeTS time.Time
+		skew        time.Duration
+		expected    error
+	}{
+		{testName: "Fresh", challengeTS: now.Add(-time.Minute)},
+		{testName: "AtMaxAge", challengeTS: now.Add(-2 * time.Minute)},
+		{testName: "Expired", challengeTS: now.Add(-3 * time.Minute), expected: ErrChallengeExpired},
+		{testName: "ExpiredWithinSkew", challengeTS: now.Add(-2*time.Minute - time.Second), skew: 5 * time.Second},
+		{testName: "Zero", challengeTS: time.Time{}, expected: ErrChallengeExpired},
+		{testName: "Future", challengeTS: now.Add(time.Second), expected: ErrChallengeInFuture},
+		{testName: "FutureWithinSkew", challengeTS: now.Add(time.Second), skew: 5 * time.Second},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// arrange
+			resp := Response{Success: true, Action: defaultAction, Score: 1.0, ChallengeTS: tc.challengeTS}
+
+			// act
+			err := resp.Verify(defaultAction, defaultMinScore, nil,
+				WithMaxAge(2*time.Minute), WithClockSkew(tc.skew), WithClock(clock))
+
+			// assert
+			if tc.expected == nil {
+				if err != nil {
+					t.Errorf("want: <nil> got: '%v'", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("want: '%v' got: '%v'", tc.expected, err)
+			}
+
+			var target *ChallengeTimeError
+			if !errors.As(err, &target) {
+				t.Fatalf("want: %T got: %T", target, err)
+			}
+
+			if !target.ChallengeTS.Equal(tc.challengeTS) || !target.Now.Equal(now) {
+				t.Errorf("want: '%v' '%v' got: '%v' '%v'", tc.challengeTS, now, target.ChallengeTS, target.Now)
+			}
+		})
+	}
+}
+
+func TestChallengeTimeError_ErrorString(t *testing.T) {
+	now := time.Date(2020, 1, 24, 14, 47, 44, 0, time.UTC)
+
+	expired := &ChallengeTimeError{ChallengeTS: now.Add(-time.Hour), Now: now, MaxAge: 2 * time.Minute}
+	if expected := "recaptchav3: challenge_ts '2020-01-24T13:47:44Z' older than '2m0s'"; expected != expired.Error() {
+		t.Errorf("want: '%v' got: '%v'", expected, expired.Error())
+	}
+
+	future := &ChallengeTimeError{ChallengeTS: now.Add(time.Hour), Now: now, MaxAge: 2 * time.Minute}
+	if expected := "recaptchav3: challenge_ts '2020-01-24T15:47:44Z' is in the future"; expected != future.Error() {
+		t.Errorf("want: '%v' got: '%v'", expected, future.Error())
+	}
+}
+
+func TestResponse_Verify_APKPackageName(t *testing.T) {
+	hostnames := []string{"example.com"}
+
+	cases := []struct {
+		testName string
+
+		response Response
+		opts     []VerifyOption
+		expected error
+	}{
+		{
+			testName: "Web",
+			response: Response{Success: true, Action: defaultAction, Score: 1.0, Hostname: "example.com"},
+			opts:     []VerifyOption{WithAPKPackageNames("com.example.app")},
+		},
+		{
+			testName: "Android",
+			response: Response{Success: true, Action: defaultAction, Score: 1.0, APKPackageName: "com.example.app"},
+			opts:     []VerifyOption{WithAPKPackageNames("com.example.app")},
+		},
+		{
+			testName: "AndroidMismatch",
+			response: Response{Success: true, Action: defaultAction, Score: 1.0, APKPackageName: "com.evil.app"},
+			opts:     []VerifyOption{WithAPKPackageNames("com.example.app")},
+			expected: ErrAPKPackageNameMismatch,
+		},
+		{
+			testName: "AndroidWithoutOption",
+			response: Response{Success: true, Action: defaultAction, Score: 1.0, APKPackageName: "com.example.app"},
+			expected: ErrHostnameMismatch,
+		},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			err := tc.response.Verify(defaultAction, defaultMinScore, hostnames, tc.opts...)
+
+			// assert
+			if tc.expected == nil && err != nil {
+				t.Errorf("want: <nil> got: '%v'", err)
+			} else if tc.expected != nil && !errors.Is(err, tc.expected) {
+				t.Errorf("want: '%v' got: '%v'", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestResponseDeserialize_APKPackageName(t *testing.T) {
+	// arrange
+	const responseJSON = `{
+  "success": true,
+  "score": 0.9,
+  "action": "homepage",
+  "challenge_ts": "2020-01-24T14:47:44Z",
+  "apk_package_name": "com.example.app",
+  "error-codes": []
+}`
+
+	// act
+	var actual Response
+	if err := json.Unmarshal([]byte(responseJSON), &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if actual.APKPackageName != "com.example.app" {
+		t.Errorf("want: 'com.example.app' got: '%v'", actual.APKPackageName)
+	}
+}
+
+func TestResponse_VerifyV2(t *testing.T) {
+	now := time.Date(2020, 1, 24, 14, 47, 44, 0, time.UTC)
+	clock := func() time.Time { return now }
+	hostnames := []string{"example.com"}
+
+	cases := []struct {
+		testName string
+
+		response Response
+		expected error
+	}{
+		{
+			testName: "Success",
+			response: Response{Success: true, ChallengeTS: now.Add(-time.Minute), Hostname: "example.com"},
+		},
+		{
+			testName: "HostnameMismatch",
+			response: Response{Success: true, ChallengeTS: now.Add(-time.Minute), Hostname: "evil.com"},
+			expected: ErrHostnameMismatch,
+		},
+		{
+			testName: "Expired",
+			response: Response{Success: true, ChallengeTS: now.Add(-time.Hour), Hostname: "example.com"},
+			expected: ErrChallengeExpired,
+		},
+		{
+			testName: "SuccessEqualsFalse",
+			response: Response{ChallengeTS: now.Add(-time.Minute), Hostname: "example.com"},
+			expected: ErrNotSuccess,
+		},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			err := tc.response.VerifyV2(hostnames, WithMaxAge(2*time.Minute), WithClock(clock))
+
+			// assert
+			if tc.expected == nil && err != nil {
+				t.Errorf("want: <nil> got: '%v'", err)
+			} else if tc.expected != nil && !errors.Is(err, tc.expected) {
+				t.Errorf("want: '%v' got: '%v'", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestResponse_VerifyV2_ErrorCodes(t *testing.T) {
+	// arrange
+	resp := Response{ErrorCodes: []string{string(ErrorCodeTimeoutOrDuplicate)}}
+
+	// act
+	err := resp.VerifyV2(nil)
+
+	// assert
+	var target *ErrorCodesError
+	if !errors.As(err, &target) || !target.IsReplay() {
+		t.Errorf("want: timeout-or-duplicate got: '%v'", err)
+	}
+}
@@ -0,0 +1,146 @@
+package recaptchav3
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how a Client retries transient siteverify failures. Network errors, errors
+// reading the response body and responses with a status code in RetryableStatusCodes are retried.
+// Responses decoded successfully are never retried, so deterministic failures reported in the
+// error codes, such as invalid-input-secret or timeout-or-duplicate, are returned immediately.
+//
+// Retries stop early if the context is done or if the next delay would pass the context deadline.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Values less than 2 disable
+	// retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. The delay doubles with each retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts. If a Retry-After header asks for a longer delay the
+	// request is not retried. Zero means no cap.
+	MaxDelay time.Duration
+	// Jitter is the fraction (0.0 - 1.0) of each delay that is randomized to spread out retries.
+	Jitter float64
+	// RetryableStatusCodes lists the HTTP status codes that are retried. If nil,
+	// DefaultRetryableStatusCodes is used.
+	RetryableStatusCodes []int
+}
+
+// DefaultRetryableStatusCodes are the HTTP status codes retried when RetryPolicy.RetryableStatusCodes
+// is nil.
+var DefaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryPolicy is a reasonable RetryPolicy for interactive requests.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    time.Second,
+	Jitter:      0.5,
+}
+
+// WithRetry sets the retry policy. By default requests are not retried.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// allows reports whether another attempt may be made after n attempts.
+func (p RetryPolicy) allows(n int) bool {
+	return n < p.MaxAttempts
+}
+
+func (p RetryPolicy) retryableStatus(code int) bool {
+	codes := p.RetryableStatusCodes
+	if codes == nil {
+		codes = DefaultRetryableStatusCodes
+	}
+
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// delay returns how long to wait before the attempt following attempt n. A negative value means
+// the request should not be retried.
+func (p RetryPolicy) delay(n int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		if p.MaxDelay > 0 && retryAfter > p.MaxDelay {
+			return -1
+		}
+
+		return retryAfter
+	}
+
+	d := p.BaseDelay
+	for i := 1; i < n && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+
+	return d
+}
+
+// parseRetryAfter parses a Retry-After header value given in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
+
+// sleep waits for d or until ctx is done. It reports false without waiting if d is negative or
+// waiting would pass the context deadline.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d < 0 {
+		return false
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(d).After(deadline) {
+		return false
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
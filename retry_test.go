@@ -0,0 +1,217 @@
+package recaptchav3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    10 * time.Millisecond,
+}
+
+// newFlakyServer returns a server that responds with status to the first failures requests and
+// with body afterwards. The number of requests received is stored in count.
+func newFlakyServer(failures int32, status int, body string, count *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(count, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+
+		w.Write([]byte(body))
+	}))
+}
+
+func TestClient_SiteVerify_RetriesTransientStatus(t *testing.T) {
+	// arrange
+	var count int32
+
+	ts := newFlakyServer(2, http.StatusServiceUnavailable, `{"success":true,"action":"register"}`, &count)
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL), WithRetry(testRetryPolicy))
+
+	// act
+	response := c.SiteVerify(context.Background(), "abc", "")
+
+	// assert
+	if err := response.Verify("register", 0, nil); err != nil {
+		t.Errorf("want: <nil> got: '%v'", err)
+	}
+
+	if count != 3 {
+		t.Errorf("attempts, want: 3 got: %d", count)
+	}
+}
+
+func TestClient_SiteVerify_RetriesExhausted(t *testing.T) {
+	// arrange
+	var count int32
+
+	ts := newFlakyServer(5, http.StatusBadGateway, `{"success":true}`, &count)
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL), WithRetry(testRetryPolicy))
+
+	// act
+	response := c.SiteVerify(context.Background(), "abc", "")
+
+	// assert
+	if response.err == nil {
+		t.Error("want: error got: <nil>")
+	}
+
+	if count != 3 {
+		t.Errorf("attempts, want: 3 got: %d", count)
+	}
+}
+
+func TestClient_SiteVerify_NoRetryOnErrorCodes(t *testing.T) {
+	// arrange
+	var count int32
+
+	ts := newFlakyServer(0, 0, `{"success":false,"error-codes":["invalid-input-secret"]}`, &count)
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL), WithRetry(testRetryPolicy))
+
+	// act
+	response := c.SiteVerify(context.Background(), "abc", "")
+
+	// assert
+	if !reflect.DeepEqual([]string{"invalid-input-secret"}, response.ErrorCodes) {
+		t.Errorf("ErrorCodes, want: '[invalid-input-secret]' got: '%v'", response.ErrorCodes)
+	}
+
+	if count != 1 {
+		t.Errorf("attempts, want: 1 got: %d", count)
+	}
+}
+
+func TestClient_SiteVerify_NoRetryOnNonRetryableStatus(t *testing.T) {
+	// arrange
+	var count int32
+
+	ts := newFlakyServer(5, http.StatusBadRequest, `{"success":true}`, &count)
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL), WithRetry(testRetryPolicy))
+
+	// act
+	c.SiteVerify(context.Background(), "abc", "")
+
+	// assert
+	if count != 1 {
+		t.Errorf("attempts, want: 1 got: %d", count)
+	}
+}
+
+func TestClient_SiteVerify_NoRetryPastDeadline(t *testing.T) {
+	// arrange
+	var count int32
+
+	ts := newFlakyServer(5, http.StatusServiceUnavailable, `{"success":true}`, &count)
+	defer ts.Close()
+
+	policy := testRetryPolicy
+	policy.BaseDelay = time.Second
+	policy.MaxDelay = time.Second
+
+	c := NewClient(WithURL(ts.URL), WithRetry(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// act
+	c.SiteVerify(ctx, "abc", "")
+
+	// assert
+	if count != 1 {
+		t.Errorf("attempts, want: 1 got: %d", count)
+	}
+}
+
+func TestClient_SiteVerify_RetryAfterAboveMaxDelay(t *testing.T) {
+	// arrange
+	var count int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL), WithRetry(testRetryPolicy))
+
+	// act
+	c.SiteVerify(context.Background(), "abc", "")
+
+	// assert
+	if count != 1 {
+		t.Errorf("attempts, want: 1 got: %d", count)
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	cases := []struct {
+		n          int
+		retryAfter time.Duration
+		expected   time.Duration
+	}{
+		{n: 1, expected: 100 * time.Millisecond},
+		{n: 2, expected: 200 * time.Millisecond},
+		{n: 3, expected: 400 * time.Millisecond},
+		{n: 5, expected: time.Second},
+		{n: 50, expected: time.Second},
+		{n: 1, retryAfter: 500 * time.Millisecond, expected: 500 * time.Millisecond},
+		{n: 1, retryAfter: 2 * time.Second, expected: -1},
+	}
+
+	for _, tc := range cases {
+		if actual := policy.delay(tc.n, tc.retryAfter); tc.expected != actual {
+			t.Errorf("delay(%d, %v), want: %v got: %v", tc.n, tc.retryAfter, tc.expected, actual)
+		}
+	}
+}
+
+func TestRetryPolicy_DelayJitter(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		if d := policy.delay(1, 0); d < 50*time.Millisecond || d > 100*time.Millisecond {
+			t.Fatalf("want: 50ms - 100ms got: %v", d)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 24, 14, 47, 44, 0, time.UTC)
+
+	cases := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{value: "", expected: 0},
+		{value: "3", expected: 3 * time.Second},
+		{value: "-3", expected: 0},
+		{value: "Fri, 24 Jan 2020 14:47:54 GMT", expected: 10 * time.Second},
+		{value: "Fri, 24 Jan 2020 14:47:34 GMT", expected: 0},
+		{value: "bogus", expected: 0},
+	}
+
+	for _, tc := range cases {
+		if actual := parseRetryAfter(tc.value, now); tc.expected != actual {
+			t.Errorf("parseRetryAfter('%s'), want: %v got: %v", tc.value, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package recaptchav3
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretProvider returns the secret key sent to the siteverify endpoint. It is called for each
+// verification, so a provider can return a new key without restarting the application. Set one
+// with WithSecretProvider.
+type SecretProvider interface {
+	Secret(ctx context.Context) (string, error)
+}
+
+// StaticSecret is a SecretProvider that always returns the same key.
+type StaticSecret string
+
+// Secret returns s.
+func (s StaticSecret) Secret(context.Context) (string, error) {
+	return string(s), nil
+}
+
+// EnvSecret returns a SecretProvider that reads the key from the named environment variable each
+// time it is called. It returns an error if the variable is not set or is empty.
+func EnvSecret(name string) SecretProvider {
+	return envSecret(name)
+}
+
+type envSecret string
+
+func (name envSecret) Secret(context.Context) (string, error) {
+	secret := os.Getenv(string(name))
+	if secret == "" {
+		return "", fmt.Errorf("recaptchav3: environment variable '%s' is not set", string(name))
+	}
+
+	return secret, nil
+}
+
+// FileSecret returns a SecretProvider that reads the key from the file at path, such as a mounted
+// Kubernetes secret. Leading and trailing whitespace is removed. The file is read again when its
+// modification time or size changes. It returns an error if the file cannot be read or is empty.
+func FileSecret(path string) SecretProvider {
+	return &fileSecret{path: path}
+}
+
+type fileSecret struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	secret  string
+}
+
+func (f *fileSecret) Secret(context.Context) (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("recaptchav3: secret file: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.secret != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.secret, nil
+	}
+
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("recaptchav3: secret file: %w", err)
+	}
+
+	secret := strings.TrimSpace(string(b))
+	if secret == "" {
+		return "", fmt.Errorf("recaptchav3: secret file '%s' is empty", f.path)
+	}
+
+	f.secret, f.modTime, f.size = secret, info.ModTime(), info.Size()
+
+	return secret, nil
+}
+
+// RotatingSecret is a SecretProvider for rotating the secret key. Tokens are verified with the
+// Current key, and if the siteverify endpoint rejects it with invalid-input-secret, verified again
+// with the Previous key until the rollover window ends. This allows the key to be changed on every
+// instance of an application without rejecting tokens while the change is rolled out.
+type RotatingSecret struct {
+	Current  SecretProvider
+	Previous SecretProvider
+	// Until is the end of the rollover window, after which the Previous key is no longer tried.
+	// If zero the window does not end.
+	Until time.Time
+}
+
+// Secret returns the Current key.
+func (r *RotatingSecret) Secret(ctx context.Context) (string, error) {
+	return r.Current.Secret(ctx)
+}
+
+// previous returns the Previous key. It reports false if the rollover window has ended or the key
+// cannot be read.
+func (r *RotatingSecret) previous(ctx context.Context) (string, bool) {
+	if r.Previous == nil || (!r.Until.IsZero() && time.Now().After(r.Until)) {
+		return "", false
+	}
+
+	secret, err := r.Previous.Secret(ctx)
+
+	return secret, err == nil && secret != ""
+}
+
+// secretRejected reports whether the siteverify endpoint rejected the secret key.
+func secretRejected(r Response) bool {
+	for _, code := range r.Codes() {
+		if code == ErrorCodeInvalidInputSecret {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,174 @@
+package recaptchav3
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newSecretServer returns a siteverify server that only accepts the secret key secretKey and
+// counts the requests.
+func newSecretServer(secretKey string, count *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(count, 1)
+
+		if r.PostFormValue("secret") != secretKey {
+			fmt.Fprintf(w, `{"success":false,"error-codes":["%s"]}`, ErrorCodeInvalidInputSecret)
+			return
+		}
+
+		w.Write([]byte(`{"success":true,"action":"homepage","score":0.9}`))
+	}))
+}
+
+func TestStaticSecret(t *testing.T) {
+	// act
+	secret, err := StaticSecret("abc").Secret(context.Background())
+
+	// assert
+	if err != nil || secret != "abc" {
+		t.Errorf("want: 'abc' <nil> got: '%v' '%v'", secret, err)
+	}
+}
+
+func TestEnvSecret(t *testing.T) {
+	// arrange
+	const name = "RECAPTCHAV3_TEST_SECRET"
+
+	defer os.Unsetenv(name)
+
+	p := EnvSecret(name)
+
+	// act
+	_, unsetErr := p.Secret(context.Background())
+
+	os.Setenv(name, "abc")
+	secret, err := p.Secret(context.Background())
+
+	// assert
+	if unsetErr == nil {
+		t.Error("unset, want: error got: <nil>")
+	}
+
+	if err != nil || secret != "abc" {
+		t.Errorf("want: 'abc' <nil> got: '%v' '%v'", secret, err)
+	}
+}
+
+func TestFileSecret(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "recaptchav3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "secret")
+	if err := ioutil.WriteFile(path, []byte("first\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := FileSecret(path)
+
+	// act
+	first, err := p.Secret(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("second-key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := p.Secret(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Remove(path)
+	_, removedErr := p.Secret(context.Background())
+
+	// assert
+	if first != "first" || second != "second-key" {
+		t.Errorf("want: 'first' 'second-key' got: '%v' '%v'", first, second)
+	}
+
+	if removedErr == nil {
+		t.Error("removed, want: error got: <nil>")
+	}
+}
+
+func TestClient_SiteVerify_SecretProviderError(t *testing.T) {
+	// arrange
+	var count int32
+
+	ts := newSecretServer("abc", &count)
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL), WithSecretProvider(EnvSecret("RECAPTCHAV3_TEST_UNSET")))
+
+	// act
+	err := c.SiteVerify(context.Background(), "token", "").Verify(defaultAction, defaultMinScore, nil)
+
+	// assert
+	if err == nil || count != 0 {
+		t.Errorf("want: error and no requests got: '%v' %d", err, count)
+	}
+}
+
+func TestClient_SiteVerify_RotatingSecret(t *testing.T) {
+	cases := []struct {
+		testName string
+
+		accepted string
+		until    time.Time
+		expected int32
+		success  bool
+	}{
+		{testName: "Current", accepted: "new", expected: 1, success: true},
+		{testName: "Previous", accepted: "old", expected: 2, success: true},
+		{testName: "Neither", accepted: "other", expected: 2},
+		{testName: "WindowEnded", accepted: "old", until: time.Now().Add(-time.Minute), expected: 1},
+		{testName: "WithinWindow", accepted: "old", until: time.Now().Add(time.Hour), expected: 2, success: true},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// arrange
+			var count int32
+
+			ts := newSecretServer(tc.accepted, &count)
+			defer ts.Close()
+
+			client := NewClient(
+				WithURL(ts.URL),
+				WithTokenStore(NewMemoryTokenStore(), time.Minute),
+				WithSecretProvider(&RotatingSecret{
+					Current:  StaticSecret("new"),
+					Previous: StaticSecret("old"),
+					Until:    tc.until,
+				}),
+			)
+
+			// act
+			err := client.SiteVerify(context.Background(), "token", "").Verify(defaultAction, defaultMinScore, nil)
+
+			// assert
+			if tc.success != (err == nil) {
+				t.Errorf("success, want: %v got: '%v'", tc.success, err)
+			}
+
+			if count != tc.expected {
+				t.Errorf("requests, want: %d got: %d", tc.expected, count)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package recaptchav3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrUnknownSite is returned by Response.Verify for a response from Client.SiteVerifySite when the
+// Client has no site with the requested ID, and is the Decision reason when Middleware cannot find a
+// site for a request.
+var ErrUnknownSite = errors.New("recaptchav3: unknown site")
+
+// Site is the configuration of one of the reCAPTCHA sites verified by a Client, for backends that
+// serve many sites each with their own key pair. Register sites with WithSites.
+type Site struct {
+	// ID identifies the site, such as a tenant name.
+	ID string
+	// Secret provides the site's secret key.
+	Secret SecretProvider
+	// Hostnames are the site's hostnames, which may use the patterns described in HostnameMatcher.
+	// They select the site for a request Host in Client.SiteForHost and are the allowed response
+	// hostnames for rules without Hostnames.
+	Hostnames []string
+	// Policy is the site's policy. If nil, the policy passed to Middleware is used.
+	Policy *Policy
+}
+
+// Evaluate evaluates r against the site's Policy, as Policy.Evaluate does, checking the response
+// hostname against the site's Hostnames if the rule has none. The decision records the site ID.
+func (s Site) Evaluate(action string, r Response) Decision {
+	if s.Policy == nil {
+		return Decision{Action: action, Score: r.Score, Provider: r.Provider, Site: s.ID, Reason: ErrNoRule}
+	}
+
+	d := s.Policy.evaluate(action, r, s.Hostnames)
+	d.Site = s.ID
+
+	return d
+}
+
+// WithSites registers sites with the Client. A site with the same ID as an earlier one replaces it.
+func WithSites(sites ...Site) Option {
+	return func(c *Client) {
+		if c.sites == nil {
+			c.sites = make(map[string]Site, len(sites))
+		}
+
+		for _, site := range sites {
+			if _, ok := c.sites[site.ID]; !ok {
+				c.siteIDs = append(c.siteIDs, site.ID)
+			}
+
+			c.sites[site.ID] = site
+		}
+	}
+}
+
+// Site returns the site registered with id.
+func (c *Client) Site(id string) (Site, bool) {
+	site, ok := c.sites[id]
+	return site, ok
+}
+
+// SiteForHost returns the first registered site with a hostname matching host, which may include a
+// port, such as the Host of an HTTP request.
+func (c *Client) SiteForHost(host string) (Site, bool) {
+	for _, id := range c.siteIDs {
+		site := c.sites[id]
+
+		m := HostnameMatcher{Patterns: site.Hostnames, StripPort: true}
+		if m.Match(host) {
+			return site, true
+		}
+	}
+
+	return Site{}, false
+}
+
+// SiteVerifySite is like SiteVerify using the secret key of the site registered with siteID.
+func (c *Client) SiteVerifySite(ctx context.Context, siteID, captchaResponse, remoteIP string) Response {
+	site, ok := c.sites[siteID]
+	if !ok {
+		return Response{err: ErrUnknownSite}
+	}
+
+	secret := site.Secret
+	if secret == nil {
+		secret = StaticSecret("")
+	}
+
+	return c.siteVerify(ctx, secret, captchaResponse, remoteIP)
+}
+
+// SiteVerifySiteRequest is like SiteVerifySite for a token received in r, as with
+// SiteVerifyRequest.
+func (c *Client) SiteVerifySiteRequest(r *http.Request, siteID, captchaResponse string) Response {
+	return c.SiteVerifySite(r.Context(), siteID, captchaResponse, RemoteIP(r, c.trustedProxies))
+}
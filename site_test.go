@@ -0,0 +1,137 @@
+package recaptchav3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newSiteServer returns a siteverify server that responds with success for the action "login",
+// using the token as the hostname, and records the secret keys it receives.
+func newSiteServer(secrets *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*secrets = append(*secrets, r.PostFormValue("secret"))
+		fmt.Fprintf(w, `{"success":true,"action":"login","score":0.9,"hostname":"%s"}`, r.PostFormValue("response"))
+	}))
+}
+
+var testSites = []Site{
+	{ID: "a", Secret: StaticSecret("a-secret"), Hostnames: []string{"a.example.com"}},
+	{ID: "b", Secret: StaticSecret("b-secret"), Hostnames: []string{".b.example.com"}},
+}
+
+func TestClient_SiteForHost(t *testing.T) {
+	client := NewClient(WithSites(testSites...))
+
+	cases := []struct {
+		testName string
+
+		host     string
+		expected string
+		ok       bool
+	}{
+		{testName: "Exact", host: "a.example.com", expected: "a", ok: true},
+		{testName: "Port", host: "a.example.com:8080", expected: "a", ok: true},
+		{testName: "Subdomain", host: "www.b.example.com", expected: "b", ok: true},
+		{testName: "Unknown", host: "c.example.com"},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			site, ok := client.SiteForHost(tc.host)
+
+			// assert
+			if ok != tc.ok || site.ID != tc.expected {
+				t.Errorf("want: '%v' %v got: '%v' %v", tc.expected, tc.ok, site.ID, ok)
+			}
+		})
+	}
+}
+
+func TestClient_SiteVerifySite(t *testing.T) {
+	// arrange
+	var secrets []string
+
+	ts := newSiteServer(&secrets)
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL), WithSites(testSites...))
+
+	// act
+	response := c.SiteVerifySite(context.Background(), "b", "www.b.example.com", "")
+	unknown := c.SiteVerifySite(context.Background(), "c", "c.example.com", "")
+
+	// assert
+	if err := response.Verify("login", 0.5, nil); err != nil {
+		t.Errorf("want: <nil> got: '%v'", err)
+	}
+
+	if len(secrets) != 1 || secrets[0] != "b-secret" {
+		t.Errorf("secrets, want: [b-secret] got: %v", secrets)
+	}
+
+	if err := unknown.Verify("login", 0.5, nil); !errors.Is(err, ErrUnknownSite) {
+		t.Errorf("want: '%v' got: '%v'", ErrUnknownSite, err)
+	}
+}
+
+func TestSite_Evaluate(t *testing.T) {
+	policy := &Policy{
+		Actions: map[string]Rule{
+			"login":  {MinScore: 0.5},
+			"signup": {MinScore: 0.5, Hostnames: []string{"signup.example.com"}},
+		},
+	}
+
+	site := Site{ID: "a", Hostnames: []string{"a.example.com"}, Policy: policy}
+
+	cases := []struct {
+		testName string
+
+		action   string
+		hostname string
+		expected error
+	}{
+		{testName: "SiteHostname", action: "login", hostname: "a.example.com"},
+		{testName: "SiteHostnameMismatch", action: "login", hostname: "b.example.com", expected: ErrHostnameMismatch},
+		{testName: "RuleHostname", action: "signup", hostname: "signup.example.com"},
+		{testName: "RuleHostnameMismatch", action: "signup", hostname: "a.example.com", expected: ErrHostnameMismatch},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// arrange
+			r := Response{Success: true, Action: tc.action, Score: 0.9, Hostname: tc.hostname}
+
+			// act
+			d := site.Evaluate(tc.action, r)
+
+			// assert
+			if tc.expected == nil && !d.Allowed() {
+				t.Errorf("want: allow got: %v '%v'", d.Outcome, d.Reason)
+			} else if tc.expected != nil && !errors.Is(d.Reason, tc.expected) {
+				t.Errorf("want: '%v' got: '%v'", tc.expected, d.Reason)
+			}
+
+			if d.Site != "a" {
+				t.Errorf("Site, want: 'a' got: '%v'", d.Site)
+			}
+		})
+	}
+}
+
+func TestSite_Evaluate_NoPolicy(t *testing.T) {
+	// act
+	d := Site{ID: "a"}.Evaluate("login", Response{Success: true, Action: "login", Score: 0.9})
+
+	// assert
+	if d.Allowed() || !errors.Is(d.Reason, ErrNoRule) {
+		t.Errorf("want: deny '%v' got: %v '%v'", ErrNoRule, d.Outcome, d.Reason)
+	}
+}
@@ -2,15 +2,30 @@ package recaptchav3
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
-const siteVerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+const (
+	siteVerifyURL         = "https://www.google.com/recaptcha/api/siteverify"
+	recaptchaNetVerifyURL = "https://www.recaptcha.net/recaptcha/api/siteverify"
+)
+
+// DefaultMaxResponseSize is the default maximum size of a response body. See WithMaxResponseSize.
+const DefaultMaxResponseSize = 64 << 10
+
+// maxBodySnippet is the maximum number of bytes of a response body included in an error.
+const maxBodySnippet = 512
 
 // SiteVerify makes a request to https://www.google.com/recaptcha/api/siteverify and returns the
 // response. Use Response.Verify to verify the response.
@@ -18,47 +33,292 @@ const siteVerifyURL = "https://www.google.com/recaptcha/api/siteverify"
 // The remoteIP parameter is optional and may be left blank. If you use a load balancer or another web
 // server to proxy calls to your application make sure to get the correct remote IP. In your HTTP handler,
 // r.RemoteAddr will be the IP of the server calling you, likely that of the load balancer or web server.
-// Instead, use X-Real-IP or the first entry in X-Forwarded-For and make sure your load balancers and web
-// servers are configured to set these headers correctly.
+// Use RemoteIP with the addresses of your proxies; do not use the first entry in X-Forwarded-For,
+// which can be set by the client.
+//
+// SiteVerify uses a default Client backed by http.DefaultClient. Use NewClient to configure timeouts,
+// transports or the endpoint URL.
 func SiteVerify(ctx context.Context, secretKey, captchaResponse, remoteIP string) Response {
-	return siteVerify(ctx, secretKey, captchaResponse, remoteIP, siteVerifyURL)
+	return defaultClient.siteVerify(ctx, StaticSecret(secretKey), captchaResponse, remoteIP)
 }
 
-func siteVerify(ctx context.Context, secretKey, captchaResponse, remoteIP, postURL string) Response {
-	data := make(url.Values, 3)
-	data.Set("secret", secretKey)
-	data.Set("response", captchaResponse)
+var defaultClient = NewClient()
+
+func (c *Client) siteVerify(ctx context.Context, secrets SecretProvider, captchaResponse, remoteIP string) Response {
+	var response Response
+	if err := c.checkToken(captchaResponse); err != nil {
+		response = Response{ErrorCodes: []string{string(err.Code)}, err: err}
+	} else {
+		response = c.verifySecret(ctx, secrets, captchaResponse, remoteIP)
+	}
+
+	response.Provider = c.provider.Name()
+
+	return response
+}
 
-	if remoteIP != "" {
-		data.Set("remoteip", remoteIP)
+// verifySecret verifies the token with the key from secrets, and with the previous key of a
+// RotatingSecret if the key is rejected.
+func (c *Client) verifySecret(ctx context.Context, secrets SecretProvider, captchaResponse, remoteIP string) Response {
+	secretKey, err := secrets.Secret(ctx)
+	if err != nil {
+		return Response{err: err}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, postURL, strings.NewReader(data.Encode()))
+	response := c.deduplicate(ctx, secretKey, captchaResponse, remoteIP)
+
+	if r, ok := secrets.(*RotatingSecret); ok && secretRejected(response) {
+		if previous, ok := r.previous(ctx); ok && previous != secretKey {
+			response = c.deduplicate(ctx, previous, captchaResponse, remoteIP)
+		}
+	}
+
+	return response
+}
+
+// deduplicate verifies the token, sharing the result with concurrent verifications of the same
+// token if WithDeduplication is used.
+func (c *Client) deduplicate(ctx context.Context, secretKey, captchaResponse, remoteIP string) Response {
+	if c.flights == nil {
+		return c.verifyToken(ctx, secretKey, captchaResponse, remoteIP)
+	}
+
+	key := flightKey(secretKey, captchaResponse, remoteIP)
+
+	return c.flights.do(ctx, key, func(ctx context.Context) Response {
+		return c.verifyToken(ctx, secretKey, captchaResponse, remoteIP)
+	})
+}
+
+// verifyToken checks the token against the Client's TokenStore, if any, before sending it.
+func (c *Client) verifyToken(ctx context.Context, secretKey, captchaResponse, remoteIP string) Response {
+	if c.tokens == nil {
+		return c.send(ctx, secretKey, captchaResponse, remoteIP)
+	}
+
+	key := tokenKey(captchaResponse)
+
+	added, err := c.tokens.Add(ctx, key, c.tokenTTL)
 	if err != nil {
-		return Response{err: fmt.Errorf("recaptchav3: %w", err)}
+		return Response{err: fmt.Errorf("recaptchav3: token store: %w", err)}
+	}
+
+	if !added {
+		return Response{err: ErrTokenReplayed}
+	}
+
+	response := c.send(ctx, secretKey, captchaResponse, remoteIP)
+	if !consumed(response.err) || secretRejected(response) {
+		// The token did not reach the endpoint or was not checked, so allow it to be verified again.
+		_ = c.tokens.Delete(context.Background(), key)
+	}
+
+	return response
+}
+
+// send makes the request to the siteverify endpoint through the rate limiter and circuit breaker, if
+// any.
+func (c *Client) send(ctx context.Context, secretKey, captchaResponse, remoteIP string) Response {
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx, remoteIP); err != nil {
+			return Response{err: err}
+		}
+	}
+
+	body := c.provider.Form(secretKey, captchaResponse, remoteIP).Encode()
+
+	if c.breaker == nil {
+		return c.exchange(ctx, secretKey, body).response
+	}
+
+	generation, ok := c.breaker.allow()
+	if !ok {
+		return c.breaker.openResponse()
+	}
+
+	a := c.exchange(ctx, secretKey, body)
+	c.breaker.record(generation, a.unavailable, ctx == nil || ctx.Err() != nil)
+
+	return a.response
+}
+
+// exchange posts body to the siteverify endpoint, retrying according to the Client's retry policy,
+// and returns the last attempt. The secret key sent in body is redacted from errors.
+func (c *Client) exchange(ctx context.Context, secretKey, body string) attempt {
+	for n := 1; ; n++ {
+		a := c.failover(ctx, secretKey, body)
+		if !a.retryable || !c.retry.allows(n) {
+			return a
+		}
+
+		if !sleep(ctx, c.retry.delay(n, a.retryAfter)) {
+			return a
+		}
+	}
+}
+
+// failover posts body to the preferred endpoint, moving on to the next endpoint while they cannot
+// be connected to, and returns the last attempt.
+func (c *Client) failover(ctx context.Context, secretKey, body string) attempt {
+	start := int(atomic.LoadInt32(&c.preferred))
+
+	var a attempt
+	for i := range c.endpoints {
+		n := (start + i) % len(c.endpoints)
+
+		a = c.post(ctx, c.endpoints[n], secretKey, body)
+		a.response.Endpoint = c.endpoints[n]
+
+		if !a.dialFailed {
+			if n != start {
+				atomic.StoreInt32(&c.preferred, int32(n))
+			}
+
+			return a
+		}
+	}
+
+	return a
+}
+
+// attempt is the outcome of a single request to the siteverify endpoint.
+type attempt struct {
+	response   Response
+	retryable  bool
+	retryAfter time.Duration
+	// unavailable reports whether the attempt failed because the endpoint could not be reached or
+	// returned a server error, as opposed to the caller canceling the request.
+	unavailable bool
+	// dialFailed reports whether the endpoint could not be connected to, so the request was not sent.
+	dialFailed bool
+}
+
+func (c *Client) post(ctx context.Context, endpoint, secretKey, body string) attempt {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(body))
+	if err != nil {
+		return attempt{response: Response{err: &TransportError{Err: err}}}
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := http.DefaultClient.Do(req)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return Response{err: fmt.Errorf("recaptchav3: %w", err)}
+		a := transportAttempt(ctx, &TransportError{Err: err})
+		a.dialFailed = a.unavailable && dialFailed(err)
+
+		return a
 	}
 	defer resp.Body.Close()
 
-	b, err := ioutil.ReadAll(resp.Body)
+	b, err := readBody(resp.Body, c.maxResponseSize)
 	if err != nil {
-		return Response{err: fmt.Errorf("recaptchav3: read body: %w", err)}
+		return transportAttempt(ctx, &TransportError{Op: "read body", Err: err})
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return Response{err: fmt.Errorf("recaptchav3: http: %s, body: '%s'", resp.Status, b)}
+		return attempt{
+			response:    Response{err: statusError(resp, bodySnippet(b, secretKey))},
+			retryable:   c.retry.retryableStatus(resp.StatusCode),
+			retryAfter:  parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			unavailable: resp.StatusCode >= http.StatusInternalServerError,
+		}
+	}
+
+	if int64(len(b)) > c.maxResponseSize {
+		return attempt{response: Response{err: &ResponseTooLargeError{Limit: c.maxResponseSize}}}
+	}
+
+	obj, err := c.provider.Decode(b)
+	if err != nil {
+		return attempt{response: Response{err: &DecodeError{Err: err, Body: bodySnippet(b, secretKey)}}}
+	}
+
+	return attempt{response: obj}
+}
+
+// transportAttempt returns the attempt for a request that failed with err. Unless the failure was
+// caused by ctx being done the endpoint is considered unavailable and the request may be retried.
+func transportAttempt(ctx context.Context, err *TransportError) attempt {
+	unavailable := ctx.Err() == nil
+
+	return attempt{
+		response:    Response{err: transportFailure(ctx, err)},
+		retryable:   unavailable,
+		unavailable: unavailable,
 	}
+}
+
+// dialFailed reports whether err is a failure to connect to the endpoint.
+func dialFailed(err error) bool {
+	var opErr *net.OpError
+
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// transportFailure returns err, marking the endpoint as unavailable unless the failure was caused
+// by ctx being done.
+func transportFailure(ctx context.Context, err *TransportError) error {
+	if ctx.Err() != nil {
+		return err
+	}
+
+	return &errUnavailable{err: err}
+}
+
+// statusError returns the error for a response with a status other than 200 OK and the body snippet
+// body. Server errors mark the endpoint as unavailable.
+func statusError(resp *http.Response, body string) error {
+	var err error = &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: body}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		err = &errUnavailable{err: err}
+	}
+
+	return err
+}
+
+// readBody reads up to limit bytes of r and one more, so callers can tell whether the body was
+// larger than limit without reading all of it.
+func readBody(r io.Reader, limit int64) ([]byte, error) {
+	return ioutil.ReadAll(io.LimitReader(r, limit+1))
+}
+
+// bodySnippet returns at most maxBodySnippet bytes of body for inclusion in an error, with secrets
+// redacted, invalid UTF-8 replaced and control characters, such as newlines, replaced by spaces.
+func bodySnippet(body []byte, secrets ...string) string {
+	s := strings.ToValidUTF8(string(body), "\uFFFD")
+
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.Replace(s, secret, "[redacted]", -1)
+			s = strings.Replace(s, url.QueryEscape(secret), "[redacted]", -1)
+		}
+	}
+
+	truncated := false
+	if len(s) > maxBodySnippet {
+		s = s[:maxBodySnippet]
+		for !utf8.ValidString(s) {
+			s = s[:len(s)-1]
+		}
+
+		truncated = true
+	}
+
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+
+		return r
+	}, s)
 
-	var obj Response
-	if err = json.Unmarshal(b, &obj); err != nil {
-		return Response{err: fmt.Errorf("recaptchav3: error decoding json: %w, body: '%s'", err, b)}
+	if truncated {
+		s += "..."
 	}
 
-	return obj
+	return s
 }
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -57,7 +58,7 @@ func TestSiteVerify_SuccessWithRemoteIP(t *testing.T) {
 	defer ts.Close()
 
 	// act
-	actual := siteVerify(ctx, secretKey, captchaToken, remoteIP, ts.URL)
+	actual := NewClient(WithURL(ts.URL), WithSecretKey(secretKey)).SiteVerify(ctx, captchaToken, remoteIP)
 
 	// assert
 	assertResponseEqual(t, expected, actual)
@@ -88,7 +89,7 @@ func TestSiteVerify_SuccessWithoutRemoteIP(t *testing.T) {
 	defer ts.Close()
 
 	// act
-	actual := siteVerify(ctx, secretKey, captchaToken, remoteIP, ts.URL)
+	actual := NewClient(WithURL(ts.URL), WithSecretKey(secretKey)).SiteVerify(ctx, captchaToken, remoteIP)
 
 	// assert
 	assertResponseEqual(t, expected, actual)
@@ -113,7 +114,7 @@ func TestSiteVerify_NilContext(t *testing.T) {
 	defer ts.Close()
 
 	// act
-	actual := siteVerify(ctx, secretKey, captchaToken, remoteIP, ts.URL)
+	actual := NewClient(WithURL(ts.URL), WithSecretKey(secretKey)).SiteVerify(ctx, captchaToken, remoteIP)
 
 	// assert
 	assertResponseEqual(t, expected, actual)
@@ -141,7 +142,7 @@ func TestSiteVerify_HTTP500(t *testing.T) {
 	defer ts.Close()
 
 	// act
-	actual := siteVerify(ctx, secretKey, captchaToken, remoteIP, ts.URL)
+	actual := NewClient(WithURL(ts.URL), WithSecretKey(secretKey)).SiteVerify(ctx, captchaToken, remoteIP)
 
 	// assert
 	assertResponseEqual(t, expected, actual)
@@ -170,7 +171,7 @@ func TestSiteVerify_BadJSONResponse(t *testing.T) {
 	defer ts.Close()
 
 	// act
-	actual := siteVerify(ctx, secretKey, captchaToken, remoteIP, ts.URL)
+	actual := NewClient(WithURL(ts.URL), WithSecretKey(secretKey)).SiteVerify(ctx, captchaToken, remoteIP)
 
 	// assert
 	assertResponseEqual(t, expected, actual)
@@ -196,16 +197,21 @@ func TestSiteVerify_ContextTimeout(t *testing.T) {
 		ChallengeTS: time.Time{},
 		Hostname:    "",
 		ErrorCodes:  nil,
-		err:         fmt.Errorf("recaptchav3: Post %s: context deadline exceeded", ts.URL),
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
 	defer cancel()
 
 	// act
-	actual := siteVerify(ctx, secretKey, captchaToken, remoteIP, ts.URL)
+	actual := NewClient(WithURL(ts.URL), WithSecretKey(secretKey)).SiteVerify(ctx, captchaToken, remoteIP)
 
 	// assert
+	var target *TransportError
+	if !errors.As(actual.err, &target) || !errors.Is(actual.err, context.DeadlineExceeded) {
+		t.Errorf("err, want: %T '%v' got: '%v'", target, context.DeadlineExceeded, actual.err)
+	}
+
+	actual.err = nil
 	assertResponseEqual(t, expected, actual)
 }
 
@@ -229,16 +235,21 @@ func TestSiteVerify_ContextCanceled(t *testing.T) {
 		ChallengeTS: time.Time{},
 		Hostname:    "",
 		ErrorCodes:  nil,
-		err:         fmt.Errorf("recaptchav3: Post %s: context canceled", ts.URL),
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
 	// act
-	actual := siteVerify(ctx, secretKey, captchaToken, remoteIP, ts.URL)
+	actual := NewClient(WithURL(ts.URL), WithSecretKey(secretKey)).SiteVerify(ctx, captchaToken, remoteIP)
 
 	// assert
+	var target *TransportError
+	if !errors.As(actual.err, &target) || !errors.Is(actual.err, context.Canceled) {
+		t.Errorf("err, want: %T '%v' got: '%v'", target, context.Canceled, actual.err)
+	}
+
+	actual.err = nil
 	assertResponseEqual(t, expected, actual)
 }
 
@@ -381,7 +392,7 @@ func TestSiteVerify_SendsParamsWithRemoteIP(t *testing.T) {
 	const expected = "remoteip=127.0.0.1&response=de%3Df&secret=ab%26c"
 
 	// act
-	response := siteVerify(ctx, secretKey, captchaToken, remoteIP, ts.URL)
+	response := NewClient(WithURL(ts.URL), WithSecretKey(secretKey)).SiteVerify(ctx, captchaToken, remoteIP)
 	if err := response.Verify("", 0, nil); err != nil {
 		t.Fatal(err)
 	}
@@ -409,7 +420,7 @@ func TestSiteVerify_SendsParamsWithoutRemoteIP(t *testing.T) {
 	const expected = "response=de%3Df&secret=ab%26c"
 
 	// act
-	response := siteVerify(ctx, secretKey, captchaToken, "", ts.URL)
+	response := NewClient(WithURL(ts.URL), WithSecretKey(secretKey)).SiteVerify(ctx, captchaToken, "")
 	if err := response.Verify("", 0, nil); err != nil {
 		t.Fatal(err)
 	}
@@ -433,8 +444,174 @@ func TestSiteVerify_ReadBodyError(t *testing.T) {
 	}
 
 	// act
-	actual := siteVerify(context.Background(), "", "", "", ts.URL)
+	actual := NewClient(WithURL(ts.URL)).SiteVerify(context.Background(), "abc", "")
 
 	// assert
 	assertResponseEqual(t, expected, actual)
 }
+
+func TestSiteVerify_ErrorTypes(t *testing.T) {
+	t.Run("HTTPStatus", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("bad gateway"))
+		}))
+		defer ts.Close()
+
+		err := NewClient(WithURL(ts.URL)).SiteVerify(context.Background(), "abc", "").Verify("", 0, nil)
+
+		var target *HTTPStatusError
+		if !errors.As(err, &target) {
+			t.Fatalf("want: %T got: %T", target, err)
+		}
+
+		if target.StatusCode != http.StatusBadGateway || target.Body != "bad gateway" {
+			t.Errorf("want: 502 'bad gateway' got: %d '%s'", target.StatusCode, target.Body)
+		}
+
+		if !IsUnavailable(err) {
+			t.Error("IsUnavailable, want: true got: false")
+		}
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("bogus response"))
+		}))
+		defer ts.Close()
+
+		err := NewClient(WithURL(ts.URL)).SiteVerify(context.Background(), "abc", "").Verify("", 0, nil)
+
+		var target *DecodeError
+		if !errors.As(err, &target) {
+			t.Fatalf("want: %T got: %T", target, err)
+		}
+
+		if IsUnavailable(err) {
+			t.Error("IsUnavailable, want: false got: true")
+		}
+	})
+
+	t.Run("Transport", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		ts.Close()
+
+		err := NewClient(WithURL(ts.URL)).SiteVerify(context.Background(), "abc", "").Verify("", 0, nil)
+
+		var target *TransportError
+		if !errors.As(err, &target) {
+			t.Fatalf("want: %T got: %T", target, err)
+		}
+
+		if !IsUnavailable(err) {
+			t.Error("IsUnavailable, want: true got: false")
+		}
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ts := newTestServer(time.Now().UTC(), slowHTTPHandler)
+		defer ts.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := NewClient(WithURL(ts.URL)).SiteVerify(ctx, "abc", "").Verify("", 0, nil)
+
+		var target *TransportError
+		if !errors.As(err, &target) {
+			t.Fatalf("want: %T got: %T", target, err)
+		}
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("want: '%v' got: '%v'", context.Canceled, err)
+		}
+
+		if IsUnavailable(err) {
+			t.Error("IsUnavailable, want: false got: true")
+		}
+	})
+}
+
+func TestSiteVerify_ResponseTooLarge(t *testing.T) {
+	// arrange
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"success":true,"hostname":"%s"}`, strings.Repeat("a", 100))
+	}))
+	defer ts.Close()
+
+	// act
+	err := NewClient(WithURL(ts.URL), WithMaxResponseSize(64)).SiteVerify(context.Background(), "abc", "").Verify("", 0, nil)
+
+	// assert
+	var target *ResponseTooLargeError
+	if !errors.As(err, &target) || target.Limit != 64 {
+		t.Errorf("want: %T 64 got: '%v'", target, err)
+	}
+
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("want: '%v' got: '%v'", ErrResponseTooLarge, err)
+	}
+}
+
+func TestSiteVerify_ErrorBodyRedacted(t *testing.T) {
+	// arrange
+	const secretKey = "s3cr&t"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprintf(w, "<html>\nrequest: %s\nsecret: %s\n%s</html>", b, secretKey, strings.Repeat("x", 1<<20))
+	}))
+	defer ts.Close()
+
+	// act
+	err := NewClient(WithURL(ts.URL), WithSecretKey(secretKey)).SiteVerify(context.Background(), "abc", "").Verify("", 0, nil)
+
+	// assert
+	var target *HTTPStatusError
+	if !errors.As(err, &target) {
+		t.Fatalf("want: %T got: %T", target, err)
+	}
+
+	if strings.Contains(err.Error(), "s3cr") {
+		t.Errorf("want: secret redacted got: '%v'", err)
+	}
+
+	const prefix = "<html> request: response=abc&secret=[redacted] secret: [redacted] xxx"
+	if !strings.HasPrefix(target.Body, prefix) || !strings.HasSuffix(target.Body, "x...") {
+		t.Errorf("want: '%s...' got: '%s'", prefix, target.Body)
+	}
+
+	if len(target.Body) > maxBodySnippet+len("...") {
+		t.Errorf("len(Body), want: <= %d got: %d", maxBodySnippet+len("..."), len(target.Body))
+	}
+}
+
+func TestBodySnippet(t *testing.T) {
+	cases := []struct {
+		testName string
+
+		body     string
+		expected string
+	}{
+		{testName: "Plain", body: "bad gateway", expected: "bad gateway"},
+		{testName: "ControlCharacters", body: "bad\r\ngateway\x1b[0m", expected: "bad  gateway [0m"},
+		{testName: "InvalidUTF8", body: "bad\xffgateway", expected: "bad�gateway"},
+		{testName: "Truncated", body: strings.Repeat("é", maxBodySnippet), expected: strings.Repeat("é", maxBodySnippet/2) + "..."},
+		{testName: "Secret", body: "secret=abc%2B1 abc+1", expected: "secret=[redacted] [redacted]"},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			actual := bodySnippet([]byte(tc.body), "", "abc+1")
+
+			// assert
+			if tc.expected != actual {
+				t.Errorf("want: '%s' got: '%s'", tc.expected, actual)
+			}
+		})
+	}
+}
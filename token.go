@@ -0,0 +1,81 @@
+package recaptchav3
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxTokenLength is the default maximum length of a token. See WithMaxTokenLength.
+const DefaultMaxTokenLength = 8192
+
+// ErrInvalidToken matches any *TokenError with errors.Is.
+var ErrInvalidToken = errors.New("recaptchav3: invalid token")
+
+// TokenError is returned by Response.Verify when a token is rejected before it is sent to the
+// siteverify endpoint because it is empty, too long or contains characters that never appear in
+// tokens. It wraps an *ErrorCodesError with the code the endpoint would have returned,
+// missing-input-response or invalid-input-response, so it is handled like that response.
+type TokenError struct {
+	Code ErrorCode
+	// Reason describes the problem with the token.
+	Reason string
+}
+
+func (e *TokenError) Error() string {
+	return fmt.Sprintf("recaptchav3: %s: %s", e.Code, e.Reason)
+}
+
+// Unwrap returns the equivalent *ErrorCodesError.
+func (e *TokenError) Unwrap() error {
+	return &ErrorCodesError{Codes: []ErrorCode{e.Code}}
+}
+
+// Is reports whether target is ErrInvalidToken.
+func (*TokenError) Is(target error) bool {
+	return target == ErrInvalidToken
+}
+
+// WithMaxTokenLength sets the maximum length of a token. Longer tokens are rejected with a
+// *TokenError without being sent. The default is DefaultMaxTokenLength.
+func WithMaxTokenLength(n int) Option {
+	return func(c *Client) {
+		c.maxTokenLength = n
+	}
+}
+
+// checkToken returns a *TokenError if token cannot be valid. Tokens from all supported providers
+// consist of base64 and base64url characters and dots.
+func (c *Client) checkToken(token string) *TokenError {
+	if token == "" {
+		return &TokenError{Code: ErrorCodeMissingInputResponse, Reason: "token is empty"}
+	}
+
+	if len(token) > c.maxTokenLength {
+		return &TokenError{
+			Code:   ErrorCodeInvalidInputResponse,
+			Reason: fmt.Sprintf("token length %d exceeds %d", len(token), c.maxTokenLength),
+		}
+	}
+
+	for i := 0; i < len(token); i++ {
+		if !tokenChar(token[i]) {
+			return &TokenError{
+				Code:   ErrorCodeInvalidInputResponse,
+				Reason: fmt.Sprintf("token contains invalid character at offset %d", i),
+			}
+		}
+	}
+
+	return nil
+}
+
+func tokenChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	case b == '-', b == '_', b == '.', b == '+', b == '/', b == '=':
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,83 @@
+package recaptchav3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_CheckToken(t *testing.T) {
+	client := NewClient(WithMaxTokenLength(32))
+
+	cases := []struct {
+		testName string
+
+		token    string
+		expected ErrorCode
+	}{
+		{testName: "Valid", token: "03AGdBq2-4_x.y+z/="},
+		{testName: "Empty", token: "", expected: ErrorCodeMissingInputResponse},
+		{testName: "TooLong", token: strings.Repeat("a", 33), expected: ErrorCodeInvalidInputResponse},
+		{testName: "Space", token: "abc def", expected: ErrorCodeInvalidInputResponse},
+		{testName: "HTML", token: "<script>", expected: ErrorCodeInvalidInputResponse},
+		{testName: "NonASCII", token: "abcé", expected: ErrorCodeInvalidInputResponse},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.testName, func(t *testing.T) {
+			// act
+			err := client.checkToken(tc.token)
+
+			// assert
+			if tc.expected == "" {
+				if err != nil {
+					t.Errorf("want: <nil> got: '%v'", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Code != tc.expected {
+				t.Errorf("want: %v got: '%v'", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestClient_SiteVerify_InvalidTokenNotSent(t *testing.T) {
+	// arrange
+	var requests int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL))
+
+	// act
+	response := c.SiteVerify(context.Background(), strings.Repeat("a", DefaultMaxTokenLength+1), "")
+	err := response.Verify(defaultAction, defaultMinScore, nil)
+
+	// assert
+	if requests != 0 {
+		t.Errorf("requests, want: 0 got: %d", requests)
+	}
+
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("want: '%v' got: '%v'", ErrInvalidToken, err)
+	}
+
+	var target *ErrorCodesError
+	if !errors.As(err, &target) || !target.IsClientError() {
+		t.Errorf("want: invalid-input-response got: '%v'", err)
+	}
+
+	if codes := response.Codes(); len(codes) != 1 || codes[0] != ErrorCodeInvalidInputResponse {
+		t.Errorf("Codes, want: [%s] got: %v", ErrorCodeInvalidInputResponse, codes)
+	}
+}
@@ -0,0 +1,118 @@
+package recaptchav3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTokenReplayed is returned by Response.Verify when the Client's TokenStore has already seen the
+// token. No request is made to the siteverify endpoint.
+var ErrTokenReplayed = errors.New("recaptchav3: token already verified")
+
+// DefaultTokenTTL is how long tokens are remembered when WithTokenStore is given a zero TTL. Google
+// rejects tokens older than two minutes.
+const DefaultTokenTTL = 2 * time.Minute
+
+// TokenStore records verified tokens to reject local replays. Keys are SHA-256 hashes of the
+// tokens; raw tokens are never stored. Implementations must be safe for concurrent use and may be
+// shared between processes, for example backed by Redis, so several services reject each other's
+// replays.
+type TokenStore interface {
+	// Add records key for ttl. It reports false, without error, if key is already recorded.
+	Add(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Delete removes key so it can be added again. It is called when a token could not be sent to
+	// the siteverify endpoint.
+	Delete(ctx context.Context, key string) error
+}
+
+// WithTokenStore rejects tokens already recorded in store with ErrTokenReplayed before making a
+// request. Tokens are recorded for ttl, or DefaultTokenTTL if ttl is zero.
+func WithTokenStore(store TokenStore, ttl time.Duration) Option {
+	return func(c *Client) {
+		if ttl <= 0 {
+			ttl = DefaultTokenTTL
+		}
+
+		c.tokens = store
+		c.tokenTTL = ttl
+	}
+}
+
+// tokenKey returns the TokenStore key for token.
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// consumed reports whether a request that returned err may have reached the siteverify endpoint,
+// in which case the token may have been used.
+func consumed(err error) bool {
+	var transport *TransportError
+
+	return !errors.As(err, &transport) && !IsUnavailable(err) && !errors.Is(err, ErrCircuitOpen) &&
+		!errors.Is(err, ErrRateLimited)
+}
+
+// MemoryTokenStore is an in-memory TokenStore. Expired keys are removed as new keys are added.
+type MemoryTokenStore struct {
+	mu        sync.Mutex
+	keys      map[string]time.Time
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryTokenStore returns an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		keys: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// Add implements TokenStore.
+func (s *MemoryTokenStore) Add(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	if now.After(s.nextSweep) {
+		for k, expires := range s.keys {
+			if !now.Before(expires) {
+				delete(s.keys, k)
+			}
+		}
+
+		s.nextSweep = now.Add(ttl)
+	}
+
+	if expires, ok := s.keys[key]; ok && now.Before(expires) {
+		return false, nil
+	}
+
+	s.keys[key] = now.Add(ttl)
+
+	return true, nil
+}
+
+// Delete implements TokenStore.
+func (s *MemoryTokenStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, key)
+
+	return nil
+}
+
+// Len returns the number of keys recorded, including expired keys not yet removed.
+func (s *MemoryTokenStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.keys)
+}
@@ -0,0 +1,162 @@
+package recaptchav3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryTokenStore_AddExpires(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Date(2020, 1, 24, 14, 47, 44, 0, time.UTC)}
+
+	s := NewMemoryTokenStore()
+	s.now = clock.now
+
+	// act/assert
+	if added, _ := s.Add(ctx, "a", time.Minute); !added {
+		t.Error("first add, want: true got: false")
+	}
+
+	if added, _ := s.Add(ctx, "a", time.Minute); added {
+		t.Error("second add, want: false got: true")
+	}
+
+	clock.advance(time.Minute)
+
+	if added, _ := s.Add(ctx, "a", time.Minute); !added {
+		t.Error("add after expiry, want: true got: false")
+	}
+}
+
+func TestMemoryTokenStore_Sweep(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Date(2020, 1, 24, 14, 47, 44, 0, time.UTC)}
+
+	s := NewMemoryTokenStore()
+	s.now = clock.now
+
+	s.Add(ctx, "a", time.Minute)
+	s.Add(ctx, "b", time.Minute)
+	clock.advance(2 * time.Minute)
+
+	// act
+	s.Add(ctx, "c", time.Minute)
+
+	// assert
+	if s.Len() != 1 {
+		t.Errorf("Len, want: 1 got: %d", s.Len())
+	}
+}
+
+func TestMemoryTokenStore_Delete(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	s := NewMemoryTokenStore()
+	s.Add(ctx, "a", time.Minute)
+
+	// act
+	s.Delete(ctx, "a")
+
+	// assert
+	if added, _ := s.Add(ctx, "a", time.Minute); !added {
+		t.Error("add after delete, want: true got: false")
+	}
+}
+
+func TestClient_SiteVerify_TokenReplay(t *testing.T) {
+	// arrange
+	var count int32
+
+	ts := newFlakyServer(0, 0, `{"success":true}`, &count)
+	defer ts.Close()
+
+	store := NewMemoryTokenStore()
+	c := NewClient(WithURL(ts.URL), WithTokenStore(store, 0))
+
+	// act
+	first := c.SiteVerify(context.Background(), "token", "")
+	second := c.SiteVerify(context.Background(), "token", "")
+
+	// assert
+	if err := first.Verify("", 0, nil); err != nil {
+		t.Errorf("first, want: <nil> got: '%v'", err)
+	}
+
+	if err := second.Verify("", 0, nil); !errors.Is(err, ErrTokenReplayed) {
+		t.Errorf("second, want: '%v' got: '%v'", ErrTokenReplayed, err)
+	}
+
+	if count != 1 {
+		t.Errorf("requests, want: 1 got: %d", count)
+	}
+}
+
+func TestClient_SiteVerify_TokenStoreReleasedOnUnavailable(t *testing.T) {
+	// arrange
+	var count int32
+
+	ts := newFlakyServer(1, http.StatusServiceUnavailable, `{"success":true}`, &count)
+	defer ts.Close()
+
+	c := NewClient(WithURL(ts.URL), WithTokenStore(NewMemoryTokenStore(), time.Minute))
+
+	// act
+	first := c.SiteVerify(context.Background(), "token", "")
+	second := c.SiteVerify(context.Background(), "token", "")
+
+	// assert
+	if err := first.Verify("", 0, nil); !IsUnavailable(err) {
+		t.Errorf("first, want: unavailable got: '%v'", err)
+	}
+
+	if err := second.Verify("", 0, nil); err != nil {
+		t.Errorf("second, want: <nil> got: '%v'", err)
+	}
+
+	if atomic.LoadInt32(&count) != 2 {
+		t.Errorf("requests, want: 2 got: %d", count)
+	}
+}
+
+type recordingTokenStore struct {
+	keys []string
+}
+
+func (s *recordingTokenStore) Add(_ context.Context, key string, _ time.Duration) (bool, error) {
+	s.keys = append(s.keys, key)
+	return true, nil
+}
+
+func (s *recordingTokenStore) Delete(context.Context, string) error {
+	return nil
+}
+
+func TestClient_SiteVerify_TokenStoreHashesTokens(t *testing.T) {
+	// arrange
+	var count int32
+
+	ts := newFlakyServer(0, 0, `{"success":true}`, &count)
+	defer ts.Close()
+
+	store := &recordingTokenStore{}
+	c := NewClient(WithURL(ts.URL), WithTokenStore(store, time.Minute))
+
+	// act
+	c.SiteVerify(context.Background(), "raw-token-value", "")
+
+	// assert
+	if len(store.keys) != 1 {
+		t.Fatalf("keys, want: 1 got: %d", len(store.keys))
+	}
+
+	if strings.Contains(store.keys[0], "raw-token-value") || len(store.keys[0]) != 64 {
+		t.Errorf("want: sha256 hex got: '%s'", store.keys[0])
+	}
+}